@@ -0,0 +1,106 @@
+// Command bookbuild creates a Polyglot opening book from PGN game collections.
+//
+// Usage:
+//
+//	bookbuild [-o book.bin] [-ply N] [-min N] games.pgn...
+//
+// Every game is replayed up to the given ply and its moves are weighted by the game result,
+// 2 points for the winning side and 1 for each side of a draw. Moves played in fewer than
+// -min games are left out. Games that cannot be replayed are reported and skipped.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+	"github.com/deadpyxel/cheesy/internal/book"
+	"github.com/deadpyxel/cheesy/internal/pgn"
+)
+
+func main() {
+	out := flag.String("o", "book.bin", "output book file")
+	maxPly := flag.Int("ply", 30, "plies recorded from each game, 0 for whole games")
+	minGames := flag.Int("min", 2, "minimum number of games a move must be played in")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: bookbuild [flags] games.pgn...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*out, *maxPly, *minGames, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "bookbuild: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(out string, maxPly, minGames int, paths []string) error {
+	bd := book.NewBuilder(maxPly, minGames)
+	added, skipped := 0, 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		n, err := addGames(bd, f, func(err error) {
+			skipped++
+			fmt.Fprintf(os.Stderr, "%s: skipping game: %v\n", path, err)
+		})
+		added += n
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	n, err := bd.WriteTo(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d games added, %d skipped, %d entries written to %s\n", added, skipped, n/book.EntrySize, out)
+	return nil
+}
+
+// addGames records every game read from r in the builder and returns how many were added.
+// Malformed games are passed to skip and reading goes on with the next one.
+func addGames(bd *book.Builder, r io.Reader, skip func(error)) (added int, err error) {
+	pr := pgn.NewReader(r)
+	for {
+		g, err := pr.Read()
+		if errors.Is(err, io.EOF) {
+			return added, nil
+		}
+		var syntaxErr *pgn.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			skip(err)
+			continue
+		}
+		if err != nil {
+			return added, err
+		}
+
+		moves := make([]board.Move, len(g.Moves))
+		for i, m := range g.Moves {
+			moves[i] = m.Move
+		}
+		if err := bd.AddGame(&g.Start, moves, g.Result); err != nil {
+			skip(err)
+			continue
+		}
+		added++
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+	"github.com/deadpyxel/cheesy/internal/book"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	pgn := filepath.Join(dir, "games.pgn")
+	games := `[Result "1-0"]
+1. e4 e5 2. Nf3 1-0
+
+[Result "1/2-1/2"]
+1. e4 c5 2. Nf3 1/2-1/2
+
+[Result "0-1"]
+1. d4 Nf6 0-1
+
+1. e4 e5 2. Qh5 Ke7 3. Qxe5# 1-0
+
+1. e4 e4 1-0
+`
+	if err := os.WriteFile(pgn, []byte(games), 0o644); err != nil {
+		t.Fatalf("writing test games: %v", err)
+	}
+	out := filepath.Join(dir, "book.bin")
+	if err := run(out, 2, 2, []string{pgn}); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+
+	bk, err := book.Open(out)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	defer bk.Close()
+	// e4 and e5 are the only moves within 2 plies played in at least 2 games
+	if bk.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d instead", bk.Len())
+	}
+	var b board.Board
+	b.SetInitialBoard()
+	m, ok, err := bk.BestMove(&b)
+	if err != nil || !ok {
+		t.Fatalf("expected a book move, got ok %v and error %v instead", ok, err)
+	}
+	if m.String() != "e2 -> e4" {
+		t.Errorf("expected e2 -> e4, got %v instead", m)
+	}
+
+	if err := run(out, 2, 2, []string{filepath.Join(dir, "missing.pgn")}); err == nil {
+		t.Errorf("expected error for missing file, got nil instead")
+	}
+}
@@ -0,0 +1,86 @@
+package board
+
+import "github.com/deadpyxel/cheesy/internal/utils"
+
+// Precomputed attack sets for pieces whose moves do not depend on blockers
+var (
+	knightAttacks [64]Bitboard
+	kingAttacks   [64]Bitboard
+	pawnAttacks   [2][64]Bitboard // [Color][Square]
+)
+
+func init() {
+	for sq := Square(0); sq < 64; sq++ {
+		knightAttacks[sq] = leaperAttacks(sq, KnightMoves[:], 2)
+		kingAttacks[sq] = leaperAttacks(sq, KingMoves[:], 1)
+		pawnAttacks[White][sq] = leaperAttacks(sq, []int{7, 9}, 1)
+		pawnAttacks[Black][sq] = leaperAttacks(sq, []int{-9, -7}, 1)
+	}
+}
+
+// leaperAttacks builds the attack set for jumps by the given offsets,
+// skipping targets more than maxFileDelta files away since those wrapped around the board edge.
+func leaperAttacks(sq Square, offsets []int, maxFileDelta int) Bitboard {
+	var attacks Bitboard
+	for _, offset := range offsets {
+		toSq := int(sq) + offset
+		if isOutOfBoard(toSq) || utils.Abs(sq.FileOf()-Square(toSq).FileOf()) > maxFileDelta {
+			continue
+		}
+		attacks = attacks.Set(Square(toSq))
+	}
+	return attacks
+}
+
+// KnightAttacks returns the squares attacked by a knight on sq.
+func KnightAttacks(sq Square) Bitboard {
+	return knightAttacks[sq]
+}
+
+// KingAttacks returns the squares attacked by a king on sq.
+func KingAttacks(sq Square) Bitboard {
+	return kingAttacks[sq]
+}
+
+// PawnAttacks returns the squares attacked by a pawn of the given color on sq.
+func PawnAttacks(sq Square, color Color) Bitboard {
+	return pawnAttacks[color][sq]
+}
+
+// Attackers returns a Bitboard with every piece of the given color attacking the square.
+// Attacks are symmetric, so a piece attacks sq if the same piece placed on sq would attack it.
+func (b *Board) Attackers(sq Square, byColor Color) Bitboard {
+	pieces := &b.Pieces[byColor]
+	occupied := b.OccupiedSquares
+
+	// Pawns attack diagonally forward, so look with the pawn attacks of the other color
+	attackers := PawnAttacks(sq, byColor^1) & pieces[Pawn]
+	attackers |= KnightAttacks(sq) & pieces[Knight]
+	attackers |= KingAttacks(sq) & pieces[King]
+	attackers |= BishopAttacks(sq, occupied) & (pieces[Bishop] | pieces[Queen])
+	attackers |= RookAttacks(sq, occupied) & (pieces[Rook] | pieces[Queen])
+	return attackers
+}
+
+// IsSquareAttacked checks if any piece of the given color attacks the square.
+func (b *Board) IsSquareAttacked(sq Square, byColor Color) bool {
+	return b.Attackers(sq, byColor) != 0
+}
+
+// InCheck checks if the king of the side to move is attacked.
+func (b *Board) InCheck() bool {
+	kingSq, ok := b.kingSquare(b.SideToMove)
+	if !ok {
+		return false
+	}
+	return b.IsSquareAttacked(kingSq, b.SideToMove^1)
+}
+
+// kingSquare returns the square of the king of the given color, ok is false when there is no king.
+func (b *Board) kingSquare(color Color) (Square, bool) {
+	kingBB := b.Pieces[color][King]
+	if kingBB == 0 {
+		return 0, false
+	}
+	return kingBB.LSB(), true
+}
@@ -0,0 +1,136 @@
+package board
+
+import "testing"
+
+func TestBoardIsSquareAttacked(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		sq       Square
+		byColor  Color
+		expected bool
+	}{
+		{name: "white pawn attacks diagonally forward", fen: "k7/8/8/8/8/8/4P3/7K w - - 0 1", sq: Square(21), byColor: White, expected: true},
+		{name: "white pawn does not attack straight ahead", fen: "k7/8/8/8/8/8/4P3/7K w - - 0 1", sq: Square(20), byColor: White, expected: false},
+		{name: "black pawn attacks diagonally forward", fen: "k7/4p3/8/8/8/8/8/7K w - - 0 1", sq: Square(43), byColor: Black, expected: true},
+		{name: "pawn on a file does not wrap to h file", fen: "k7/8/8/8/8/8/P7/7K w - - 0 1", sq: Square(23), byColor: White, expected: false},
+		{name: "knight attacks in L shape", fen: "k7/8/8/8/4N3/8/8/7K w - - 0 1", sq: Square(45), byColor: White, expected: true},
+		{name: "knight on h file does not wrap to a file", fen: "k7/8/8/8/7N/8/8/7K w - - 0 1", sq: Square(40), byColor: White, expected: false},
+		{name: "king attacks adjacent squares", fen: "8/8/8/8/4k3/8/8/7K w - - 0 1", sq: Square(37), byColor: Black, expected: true},
+		{name: "rook attacks along the file", fen: "k3r3/8/8/8/8/8/8/7K w - - 0 1", sq: Square(4), byColor: Black, expected: true},
+		{name: "rook attack blocked by another piece", fen: "k3r3/8/8/4P3/8/8/8/7K w - - 0 1", sq: Square(4), byColor: Black, expected: false},
+		{name: "bishop attacks along the diagonal", fen: "k7/8/8/8/8/8/8/B6K w - - 0 1", sq: Square(63), byColor: White, expected: true},
+		{name: "queen attacks like a rook", fen: "k7/8/8/8/q7/8/8/7K w - - 0 1", sq: Square(31), byColor: Black, expected: true},
+		{name: "pieces of the other color do not count", fen: "k3r3/8/8/8/8/8/8/7K w - - 0 1", sq: Square(4), byColor: White, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			result := b.IsSquareAttacked(tt.sq, tt.byColor)
+			if result != tt.expected {
+				t.Errorf("expected square %s attacked by %d to be %v, got %v instead", tt.sq, tt.byColor, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBoardAttackers(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		sq       Square
+		byColor  Color
+		expected Bitboard
+	}{
+		{
+			name:     "square without attackers returns empty bitboard",
+			fen:      "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			sq:       Square(36), // e5
+			byColor:  White,
+			expected: Bitboard(0),
+		},
+		{
+			name:     "f3 is defended by pawns, knight and queen line blocked",
+			fen:      "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			sq:       Square(21), // f3
+			byColor:  White,
+			expected: Bitboard(1<<12 | 1<<14 | 1<<6), // e2, g2, g1
+		},
+		{
+			name:     "every piece type attacking the same square",
+			fen:      "4k3/8/8/3r4/2b1p3/1q6/5n2/K7 w - - 0 1",
+			sq:       Square(19), // d3
+			byColor:  Black,
+			expected: Bitboard(1<<35 | 1<<26 | 1<<17 | 1<<28 | 1<<13), // d5, c4, b3, e4, f2
+		},
+		{
+			name:     "sliders behind another attacker are not counted",
+			fen:      "k2q4/3r4/8/8/8/8/8/3K4 w - - 0 1",
+			sq:       Square(3), // d1
+			byColor:  Black,
+			expected: Bitboard(1 << 51), // d7
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			result := b.Attackers(tt.sq, tt.byColor)
+			if result != tt.expected {
+				t.Errorf("expected attackers:\n%s\n got \n%s\n", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBoardInCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		expected bool
+	}{
+		{name: "initial position is not check", fen: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", expected: false},
+		{name: "white king checked by a bishop", fen: "rnbqk1nr/pppp1ppp/8/4p3/1b1P4/8/PPP1PPPP/RNBQKBNR w KQkq - 1 3", expected: true},
+		{name: "black king checked by a knight", fen: "4k3/8/3N4/8/8/8/8/4K3 b - - 0 1", expected: true},
+		{name: "check on the other king does not count", fen: "4k3/8/3N4/8/8/8/8/4K3 w - - 0 1", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			result := b.InCheck()
+			if result != tt.expected {
+				t.Errorf("expected InCheck() to be %v, got %v instead", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLeaperAttacks(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   Bitboard
+		expected Bitboard
+	}{
+		{name: "knight on a1", result: KnightAttacks(Square(0)), expected: Bitboard(1<<10 | 1<<17)},
+		{name: "knight on h8", result: KnightAttacks(Square(63)), expected: Bitboard(1<<53 | 1<<46)},
+		{name: "king on h4 does not wrap to a file", result: KingAttacks(Square(31)), expected: Bitboard(1<<39 | 1<<38 | 1<<30 | 1<<23 | 1<<22)},
+		{name: "white pawn on a2", result: PawnAttacks(Square(8), White), expected: Bitboard(1 << 17)},
+		{name: "black pawn on e7", result: PawnAttacks(Square(52), Black), expected: Bitboard(1<<43 | 1<<45)},
+		{name: "white pawn on h8 has nothing to attack", result: PawnAttacks(Square(63), White), expected: Bitboard(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result != tt.expected {
+				t.Errorf("expected attacks:\n%s\n got \n%s\n", tt.expected, tt.result)
+			}
+		})
+	}
+}
@@ -123,3 +123,128 @@ func TestBitboardIsSet(t *testing.T) {
 		})
 	}
 }
+
+func TestBitboardCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		bb       Bitboard
+		expected int
+	}{
+		{name: "empty board has no squares", bb: Bitboard(0), expected: 0},
+		{name: "single square", bb: Bitboard(1 << 63), expected: 1},
+		{name: "whole rank", bb: Rank2, expected: 8},
+		{name: "full board", bb: Bitboard(0xFFFFFFFFFFFFFFFF), expected: 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := tt.bb.Count(); res != tt.expected {
+				t.Errorf("Expected bitboard to have %d squares set, got %d instead", tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestBitboardLSBAndMSB(t *testing.T) {
+	tests := []struct {
+		name    string
+		bb      Bitboard
+		wantLSB Square
+		wantMSB Square
+	}{
+		{name: "single square", bb: Bitboard(1 << 28), wantLSB: Square(28), wantMSB: Square(28)},
+		{name: "a1 and h8", bb: Bitboard(1 | 1<<63), wantLSB: Square(0), wantMSB: Square(63)},
+		{name: "rank 7", bb: Rank7, wantLSB: Square(48), wantMSB: Square(55)},
+		{name: "file c", bb: FileC, wantLSB: Square(2), wantMSB: Square(58)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := tt.bb.LSB(); res != tt.wantLSB {
+				t.Errorf("Expected LSB to be %s, got %s instead", tt.wantLSB, res)
+			}
+			if res := tt.bb.MSB(); res != tt.wantMSB {
+				t.Errorf("Expected MSB to be %s, got %s instead", tt.wantMSB, res)
+			}
+		})
+	}
+}
+
+func TestBitboardPopLSB(t *testing.T) {
+	bb := Bitboard(1<<3 | 1<<17 | 1<<60)
+	for _, want := range []Square{3, 17, 60} {
+		if res := bb.PopLSB(); res != want {
+			t.Errorf("Expected PopLSB to return %s, got %s instead", want, res)
+		}
+	}
+	if bb != 0 {
+		t.Errorf("Expected bitboard to be empty after popping every square, got\n%s", bb)
+	}
+}
+
+func TestBitboardSquares(t *testing.T) {
+	bb := Rank1 & (FileA | FileE | FileH)
+	var squares []Square
+	for sq := range bb.Squares() {
+		squares = append(squares, sq)
+	}
+	want := []Square{0, 4, 7}
+	if fmt.Sprint(squares) != fmt.Sprint(want) {
+		t.Errorf("Expected squares %v, got %v instead", want, squares)
+	}
+
+	// Stopping early must not visit the remaining squares
+	visited := 0
+	for range Rank2.Squares() {
+		visited++
+		if visited == 3 {
+			break
+		}
+	}
+	if visited != 3 {
+		t.Errorf("Expected iteration to stop after 3 squares, got %d", visited)
+	}
+}
+
+func TestBitboardShifts(t *testing.T) {
+	e4 := Bitboard(1 << 28)
+	tests := []struct {
+		name     string
+		result   Bitboard
+		expected Bitboard
+	}{
+		{name: "north from e4", result: e4.North(), expected: Bitboard(1 << 36)},
+		{name: "south from e4", result: e4.South(), expected: Bitboard(1 << 20)},
+		{name: "east from e4", result: e4.East(), expected: Bitboard(1 << 29)},
+		{name: "west from e4", result: e4.West(), expected: Bitboard(1 << 27)},
+		{name: "north east from e4", result: e4.NorthEast(), expected: Bitboard(1 << 37)},
+		{name: "north west from e4", result: e4.NorthWest(), expected: Bitboard(1 << 35)},
+		{name: "south east from e4", result: e4.SouthEast(), expected: Bitboard(1 << 21)},
+		{name: "south west from e4", result: e4.SouthWest(), expected: Bitboard(1 << 19)},
+		{name: "east from h file does not wrap", result: FileH.East(), expected: Bitboard(0)},
+		{name: "west from a file does not wrap", result: FileA.West(), expected: Bitboard(0)},
+		{name: "north east from h file does not wrap", result: FileH.NorthEast(), expected: Bitboard(0)},
+		{name: "south west from a file does not wrap", result: FileA.SouthWest(), expected: Bitboard(0)},
+		{name: "north from rank 8 leaves the board", result: Rank8.North(), expected: Bitboard(0)},
+		{name: "south from rank 1 leaves the board", result: Rank1.South(), expected: Bitboard(0)},
+		{name: "rank 2 north is rank 3", result: Rank2.North(), expected: Rank3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result != tt.expected {
+				t.Errorf("expected:\n%s\n got \n%s\n", tt.expected, tt.result)
+			}
+		})
+	}
+}
+
+func TestBitboardFills(t *testing.T) {
+	bb := Bitboard(1<<28 | 1<<1) // e4 and b1
+	if res, want := bb.FileFill(), FileB|FileE; res != want {
+		t.Errorf("expected file fill:\n%s\n got \n%s\n", want, res)
+	}
+	if res, want := bb.RankFill(), Rank1|Rank4; res != want {
+		t.Errorf("expected rank fill:\n%s\n got \n%s\n", want, res)
+	}
+	if res := Bitboard(0).FileFill(); res != 0 {
+		t.Errorf("expected empty file fill, got\n%s", res)
+	}
+}
@@ -4,47 +4,186 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/deadpyxel/cheesy/internal/utils"
 )
 
+// Undo holds the state lost when making a move, so UnmakeMove can restore the previous position
+type Undo struct {
+	Captured       Piece          // piece removed by the move, Empty if none
+	CastlingRights CastlingRights // castling rights before the move
+	EnPassant      Square         // en passant target before the move
+	HalfMoveClock  int            // halfmove clock before the move
+	Hash           uint64         // position key before the move
+	PawnHash       uint64         // pawn structure key before the move
+}
+
+// PlayMove plays the move on the board, discarding the information needed to take it back
 func (b *Board) PlayMove(m Move) error {
+	_, err := b.MakeMove(m)
+	return err
+}
+
+// MakeMove plays the move on the board and returns the Undo record needed by UnmakeMove.
+// On error the board is left untouched.
+func (b *Board) MakeMove(m Move) (Undo, error) {
+	undo := Undo{
+		Captured:       Empty,
+		CastlingRights: b.CastlingRights,
+		EnPassant:      b.EnPassant,
+		HalfMoveClock:  b.HalfMoveClock,
+		Hash:           b.Hash,
+		PawnHash:       b.PawnHash,
+	}
+	// Keys for the state replaced by this move, removed from the hash once the move is played
+	oldStateKeys := zobristCastling[b.CastlingRights&AllCastling] ^ b.enPassantKey()
+
 	// get moving piece from the board
-	pCol, piece := b.GetPieceAt(m.From)
+	pCol, piece := b.PieceAt(m.From)
 	if pCol == None || piece == Empty {
-		return fmt.Errorf("no piece at source square %v", m.From)
+		return undo, fmt.Errorf("no piece at source square %v", m.From)
 	}
-	// TODO: add checks for trying to move a piece not owned
 	if pCol != b.SideToMove {
-		return fmt.Errorf("cannot move opponent piece")
+		return undo, fmt.Errorf("cannot move opponent piece")
 	}
 
-	// Handle different move types
+	// Handle different move types, flags can only be combined in the listed ways
 	switch m.Type {
 	case Normal:
+		if b.OccupiedSquares.IsSet(m.To) {
+			return undo, fmt.Errorf("move %v to occupied square without capture", m)
+		}
 		b.movePiece(pCol, piece, m.From, m.To)
 	case Capture:
-		tgtCol, tgtPiece := b.GetPieceAt(m.To)
-		if tgtCol == None || tgtPiece == Empty {
-			return fmt.Errorf("capture move with no piece at target square: %v", m.To)
+		// Remove piece currently on target square and move piece to that position
+		captured, err := b.removeCaptured(m.To)
+		if err != nil {
+			return undo, err
 		}
-		// Remove piece currently on target square and move piece to taht position
-		b.Pieces[tgtCol][tgtPiece] = b.Pieces[tgtCol][tgtPiece].Clear(m.To)
+		undo.Captured = captured
 		b.movePiece(pCol, piece, m.From, m.To)
-	case Promotion:
-		b.Pieces[pCol][Pawn] = b.Pieces[pCol][Pawn].Clear(m.From)
-		b.Pieces[pCol][m.Promotion] = b.Pieces[pCol][m.Promotion].Set(m.To)
-		// TODO: Cover EnPassant, castling and Promotion + Capture cases
+	case Castle:
+		cm, ok := b.findCastlingMove(pCol, m.From, m.To)
+		if piece != King || !ok {
+			return undo, fmt.Errorf("invalid castling move %v", m)
+		}
+		if b.CastlingRights&cm.right == 0 {
+			return undo, fmt.Errorf("castling move %v without castling rights", m)
+		}
+		if !b.Pieces[pCol][Rook].IsSet(cm.rookFrom) {
+			return undo, fmt.Errorf("castling move %v without rook at %v", m, cm.rookFrom)
+		}
+		if b.OccupiedSquares&cm.path != 0 {
+			return undo, fmt.Errorf("castling move %v through occupied squares", m)
+		}
+		b.castle(pCol, cm.kingFrom, cm.rookFrom, cm.kingTo, cm.rookTo)
+	case Capture | EnPassant:
+		if piece != Pawn || m.To != b.EnPassant || b.EnPassant == NoEnPassant {
+			return undo, fmt.Errorf("invalid en passant move %v", m)
+		}
+		victim := enPassantVictim(m.To, pCol)
+		if !b.Pieces[pCol^1][Pawn].IsSet(victim) {
+			return undo, fmt.Errorf("en passant move with no pawn to capture at %v", victim)
+		}
+		undo.Captured = Pawn
+		b.removePiece(pCol^1, Pawn, victim)
+		b.movePiece(pCol, Pawn, m.From, m.To)
+	case Promotion, Capture | Promotion:
+		if piece != Pawn || m.Promotion < Knight || m.Promotion > Queen {
+			return undo, fmt.Errorf("invalid promotion move %v", m)
+		}
+		if m.Type.Has(Capture) {
+			captured, err := b.removeCaptured(m.To)
+			if err != nil {
+				return undo, err
+			}
+			undo.Captured = captured
+		} else if b.OccupiedSquares.IsSet(m.To) {
+			return undo, fmt.Errorf("promotion %v to occupied square without capture", m)
+		}
+		// Replace the pawn with the promoted piece on the target square
+		b.removePiece(pCol, Pawn, m.From)
+		b.addPiece(pCol, m.Promotion, m.To)
 	default:
-		return fmt.Errorf("unsupported move type: %v", m.Type)
+		return undo, fmt.Errorf("unsupported move type: %v", m.Type)
+	}
+
+	// Moving the king or a rook, or capturing a rook on its starting square, removes castling rights
+	mask := &b.castlingRules().mask
+	b.CastlingRights &= mask[m.From] & mask[m.To]
+
+	// Double pawn pushes leave the square they passed over as en passant target
+	b.EnPassant = NoEnPassant
+	if piece == Pawn && utils.Abs(int(m.To)-int(m.From)) == 16 {
+		b.EnPassant = Square((int(m.From) + int(m.To)) / 2)
+	}
+
+	// Pawn moves and captures reset the 50 move rule counter
+	if piece == Pawn || m.Type.Has(Capture) {
+		b.HalfMoveClock = 0
+	} else {
+		b.HalfMoveClock++
 	}
 
-	b.UpdateOccupiedSquares()
 	if b.SideToMove == Black {
 		b.FullMoveCount += 1
 	}
 
 	b.SideToMove ^= 1 // toggle active player
 
-	return nil
+	b.Hash ^= oldStateKeys ^ zobristSide ^ zobristCastling[b.CastlingRights&AllCastling] ^ b.enPassantKey()
+
+	if debugChecks {
+		if err := b.Validate(); err != nil {
+			panic(fmt.Sprintf("inconsistent board after making %v: %v", m, err))
+		}
+	}
+
+	return undo, nil
+}
+
+// UnmakeMove takes back a move played with MakeMove, restoring the exact previous position.
+// The move must be the last one made on the board, paired with the Undo record it returned.
+func (b *Board) UnmakeMove(m Move, u Undo) {
+	b.SideToMove ^= 1 // the player who made the move is active again
+	if b.SideToMove == Black {
+		b.FullMoveCount -= 1
+	}
+	pCol := b.SideToMove
+
+	switch {
+	case m.Type.Has(Castle):
+		cm, _ := b.findCastlingMove(pCol, m.From, m.To)
+		b.castle(pCol, cm.kingTo, cm.rookTo, cm.kingFrom, cm.rookFrom)
+	case m.Type.Has(EnPassant):
+		b.movePiece(pCol, Pawn, m.To, m.From)
+		victim := enPassantVictim(m.To, pCol)
+		b.addPiece(pCol^1, Pawn, victim)
+	case m.Type.Has(Promotion):
+		// Turn the promoted piece back into a pawn
+		b.removePiece(pCol, m.Promotion, m.To)
+		b.addPiece(pCol, Pawn, m.From)
+	default:
+		_, piece := b.PieceAt(m.To)
+		b.movePiece(pCol, piece, m.To, m.From)
+	}
+
+	// Put back the captured piece, en passant victims were already restored
+	if u.Captured != Empty && !m.Type.Has(EnPassant) {
+		b.addPiece(pCol^1, u.Captured, m.To)
+	}
+
+	b.CastlingRights = u.CastlingRights
+	b.EnPassant = u.EnPassant
+	b.HalfMoveClock = u.HalfMoveClock
+	b.Hash = u.Hash
+	b.PawnHash = u.PawnHash
+
+	if debugChecks {
+		if err := b.Validate(); err != nil {
+			panic(fmt.Sprintf("inconsistent board after unmaking %v: %v", m, err))
+		}
+	}
 }
 
 // PlayMoveSequence plays a sequence of moves, assuming alternating turns
@@ -72,14 +211,78 @@ func (b *Board) isEqualBoard(other Board) bool {
 	return true
 }
 
+// removeCaptured clears the opponent piece standing on the target square of a capture
+// and returns its type.
+func (b *Board) removeCaptured(sq Square) (Piece, error) {
+	tgtCol, tgtPiece := b.PieceAt(sq)
+	if tgtCol == None || tgtPiece == Empty {
+		return Empty, fmt.Errorf("capture move with no piece at target square: %v", sq)
+	}
+	if tgtCol == b.SideToMove {
+		return Empty, fmt.Errorf("capture move targets own piece at square: %v", sq)
+	}
+	b.removePiece(tgtCol, tgtPiece, sq)
+	return tgtPiece, nil
+}
+
+// movePiece, addPiece and removePiece keep the piece bitboards, occupancy, mailbox and hash in sync,
+// every change to the pieces on the board during a move goes through them.
+// Occupancy is toggled with the XOR of the touched squares instead of being recomputed.
+
 func (b *Board) movePiece(cl Color, p Piece, from, to Square) {
-	b.Pieces[cl][p] = b.Pieces[cl][p].Clear(from).Set(to)
+	fromTo := Bitboard(1)<<from | Bitboard(1)<<to
+	b.Pieces[cl][p] ^= fromTo
+	b.OccupiedByColor[cl] ^= fromTo
+	b.OccupiedSquares ^= fromTo
+	b.mailbox[from] = Empty
+	b.mailbox[to] = p
+	b.togglePieceKey(cl, p, from)
+	b.togglePieceKey(cl, p, to)
+}
+
+// castle moves king and rook together, lifting both first since in Chess960
+// either may land on the square the other one leaves, or stay where it is.
+func (b *Board) castle(cl Color, kingFrom, rookFrom, kingTo, rookTo Square) {
+	b.removePiece(cl, King, kingFrom)
+	b.removePiece(cl, Rook, rookFrom)
+	b.addPiece(cl, King, kingTo)
+	b.addPiece(cl, Rook, rookTo)
+}
+
+func (b *Board) addPiece(cl Color, p Piece, sq Square) {
+	mask := Bitboard(1) << sq
+	b.Pieces[cl][p] ^= mask
+	b.OccupiedByColor[cl] ^= mask
+	b.OccupiedSquares ^= mask
+	b.mailbox[sq] = p
+	b.togglePieceKey(cl, p, sq)
 }
 
+func (b *Board) removePiece(cl Color, p Piece, sq Square) {
+	mask := Bitboard(1) << sq
+	b.Pieces[cl][p] ^= mask
+	b.OccupiedByColor[cl] ^= mask
+	b.OccupiedSquares ^= mask
+	b.mailbox[sq] = Empty
+	b.togglePieceKey(cl, p, sq)
+}
+
+// ToFEN writes the position in Forsyth-Edwards Notation, with castling rights in X-FEN
+// when a Chess960 rook that is not the outermost one can castle.
 func (b *Board) ToFEN() string {
+	return b.fen(false)
+}
+
+// ToShredderFEN writes the position in Shredder-FEN, naming the file of each rook that can castle
+func (b *Board) ToShredderFEN() string {
+	return b.fen(true)
+}
+
+func (b *Board) fen(shredder bool) string {
 	enPassTgt := "-" // tracks en passant target square
-	castAb := "KQkq" // tracks castling privileges
-	hmClock := 0     // tracks the half move related to the 50 move draw rule
+	if b.EnPassant != NoEnPassant {
+		enPassTgt = b.EnPassant.String()
+	}
 	sideToMove := "w"
 	if b.SideToMove == Black {
 		sideToMove = "b"
@@ -92,7 +295,7 @@ func (b *Board) ToFEN() string {
 		// Traverse files 1 to 8
 		for file := 0; file < 8; file++ {
 			sq := Square(rank*8 + file)
-			color, piece := b.GetPieceAt(sq)
+			color, piece := b.PieceAt(sq)
 			if piece == Empty {
 				emptyCount++
 				// If we are at the end of a rank, append the count
@@ -116,5 +319,5 @@ func (b *Board) ToFEN() string {
 			sb.WriteRune('/')
 		}
 	}
-	return fmt.Sprintf("%s %s %s %s %d %d", sb.String(), sideToMove, castAb, enPassTgt, hmClock, b.FullMoveCount)
+	return fmt.Sprintf("%s %s %s %s %d %d", sb.String(), sideToMove, b.castlingFEN(shredder), enPassTgt, b.HalfMoveClock, b.FullMoveCount)
 }
@@ -54,6 +54,79 @@ func TestBoardGetPieceAt(t *testing.T) {
 	}
 }
 
+func TestBoardPieceAt(t *testing.T) {
+	b, err := ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatalf("invalid test FEN: %v", err)
+	}
+	var ml MoveList
+	b.GenerateLegalMoves(&ml)
+	for i := 0; i < ml.Count; i++ {
+		undo, err := b.MakeMove(ml.Moves[i])
+		if err != nil {
+			t.Fatalf("Expected no error making %v, got %v instead", ml.Moves[i], err)
+		}
+		for sq := Square(0); sq < 64; sq++ {
+			wantColor, wantPiece := b.GetPieceAt(sq)
+			gotColor, gotPiece := b.PieceAt(sq)
+			if gotColor != wantColor || gotPiece != wantPiece {
+				t.Errorf("after %v Board.PieceAt(%v) = (%v, %v), want (%v, %v)", ml.Moves[i], sq, gotColor, gotPiece, wantColor, wantPiece)
+			}
+		}
+		b.UnmakeMove(ml.Moves[i], undo)
+	}
+}
+
+func TestBoardValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*Board)
+		wantErr bool
+	}{
+		{
+			name: "initial position is consistent",
+			setup: func(b *Board) {
+				b.SetInitialBoard()
+			},
+			wantErr: false,
+		},
+		{
+			name: "two pieces on the same square",
+			setup: func(b *Board) {
+				b.SetInitialBoard()
+				b.Pieces[Black][Queen] = b.Pieces[Black][Queen].Set(Square(0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "pieces set without updating occupancy",
+			setup: func(b *Board) {
+				b.SetInitialBoard()
+				b.Pieces[White][Pawn] = b.Pieces[White][Pawn].Set(Square(28))
+			},
+			wantErr: true,
+		},
+		{
+			name: "mailbox out of sync with the bitboards",
+			setup: func(b *Board) {
+				b.SetInitialBoard()
+				b.mailbox[0] = Queen
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			tt.setup(b)
+			err := b.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Board.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestBoardUpdateOccupiedSquares(t *testing.T) {
 	b := &Board{}
 	b.SetInitialBoard()
@@ -217,6 +290,218 @@ func TestPlayMovePromotion(t *testing.T) {
 	}
 }
 
+func TestPlayMoveCapturePromotion(t *testing.T) {
+	tests := []struct {
+		name        string
+		fen         string
+		mv          Move
+		expectedFEN string
+	}{
+		{
+			name:        "white pawn on e7 captures bishop on f8 promoting to queen",
+			fen:         "4kb2/4P3/8/8/8/8/8/4K3 w - - 3 40",
+			mv:          Move{From: Square(52), To: Square(61), Type: Capture | Promotion, Promotion: Queen},
+			expectedFEN: "4kQ2/8/8/8/8/8/8/4K3 b - - 0 40",
+		},
+		{
+			name:        "black pawn on b2 captures rook on a1 promoting to knight and removes castling right",
+			fen:         "4k3/8/8/8/8/8/1p6/R3K3 b Q - 0 40",
+			mv:          Move{From: Square(9), To: Square(0), Type: Capture | Promotion, Promotion: Knight},
+			expectedFEN: "4k3/8/8/8/8/8/8/n3K3 w - - 0 41",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+
+			err = b.PlayMove(tt.mv)
+			if err != nil {
+				t.Errorf("Expected no error, got %v instead", err)
+			}
+			if result := b.ToFEN(); result != tt.expectedFEN {
+				t.Errorf("expected FEN to be %s, got %s instead", tt.expectedFEN, result)
+			}
+		})
+	}
+}
+
+func TestPlayMoveCastle(t *testing.T) {
+	tests := []struct {
+		name        string
+		fen         string
+		mv          Move
+		expectedFEN string
+	}{
+		{
+			name:        "white castles king side",
+			fen:         "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			mv:          Move{From: Square(4), To: Square(6), Type: Castle},
+			expectedFEN: "r3k2r/8/8/8/8/8/8/R4RK1 b kq - 1 1",
+		},
+		{
+			name:        "white castles queen side",
+			fen:         "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			mv:          Move{From: Square(4), To: Square(2), Type: Castle},
+			expectedFEN: "r3k2r/8/8/8/8/8/8/2KR3R b kq - 1 1",
+		},
+		{
+			name:        "black castles king side",
+			fen:         "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			mv:          Move{From: Square(60), To: Square(62), Type: Castle},
+			expectedFEN: "r4rk1/8/8/8/8/8/8/R3K2R w KQ - 1 2",
+		},
+		{
+			name:        "black castles queen side",
+			fen:         "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			mv:          Move{From: Square(60), To: Square(58), Type: Castle},
+			expectedFEN: "2kr3r/8/8/8/8/8/8/R3K2R w KQ - 1 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+
+			err = b.PlayMove(tt.mv)
+			if err != nil {
+				t.Errorf("Expected no error, got %v instead", err)
+			}
+			if result := b.ToFEN(); result != tt.expectedFEN {
+				t.Errorf("expected FEN to be %s, got %s instead", tt.expectedFEN, result)
+			}
+		})
+	}
+}
+
+func TestPlayMoveEnPassant(t *testing.T) {
+	tests := []struct {
+		name        string
+		fen         string
+		mv          Move
+		expectedFEN string
+	}{
+		{
+			name:        "white pawn captures en passant and removes the black pawn behind the target",
+			fen:         "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2",
+			mv:          Move{From: Square(36), To: Square(43), Type: Capture | EnPassant},
+			expectedFEN: "4k3/8/3P4/8/8/8/8/4K3 b - - 0 2",
+		},
+		{
+			name:        "black pawn captures en passant and removes the white pawn behind the target",
+			fen:         "4k3/8/8/8/3pP3/8/8/4K3 b - e3 5 1",
+			mv:          Move{From: Square(27), To: Square(20), Type: Capture | EnPassant},
+			expectedFEN: "4k3/8/8/8/8/4p3/8/4K3 w - - 0 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+
+			err = b.PlayMove(tt.mv)
+			if err != nil {
+				t.Errorf("Expected no error, got %v instead", err)
+			}
+			if result := b.ToFEN(); result != tt.expectedFEN {
+				t.Errorf("expected FEN to be %s, got %s instead", tt.expectedFEN, result)
+			}
+		})
+	}
+}
+
+func TestMakeUnmakeMove(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R b KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2",
+		"4k3/8/8/8/3pP3/8/8/4K3 b - e3 5 1",
+		"4k3/8/8/8/8/8/1p6/R3K3 b Q - 0 40",
+	}
+	for _, fen := range fens {
+		t.Run(fen, func(t *testing.T) {
+			b, err := ParseFEN(fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", fen, err)
+			}
+			before := *b
+
+			var ml MoveList
+			b.GenerateLegalMoves(&ml)
+			for i := 0; i < ml.Count; i++ {
+				m := ml.Moves[i]
+				undo, err := b.MakeMove(m)
+				if err != nil {
+					t.Fatalf("Expected no error making %v, got %v instead", m, err)
+				}
+				if err := b.Validate(); err != nil {
+					t.Fatalf("inconsistent board after making %v: %v", m, err)
+				}
+				b.UnmakeMove(m, undo)
+				if *b != before {
+					t.Fatalf("unmaking %v (%v) resulted in %s, want %s", m, m.Type, b.ToFEN(), before.ToFEN())
+				}
+			}
+		})
+	}
+}
+
+func TestMakeMoveUndo(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		mv       Move
+		expected Undo
+	}{
+		{
+			name:     "quiet move records no capture",
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10",
+			mv:       Move{From: Square(4), To: Square(12), Type: Normal},
+			expected: Undo{Captured: Empty, CastlingRights: AllCastling, EnPassant: NoEnPassant, HalfMoveClock: 3},
+		},
+		{
+			name:     "capture records the captured piece",
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 7 10",
+			mv:       Move{From: Square(0), To: Square(56), Type: Capture},
+			expected: Undo{Captured: Rook, CastlingRights: AllCastling, EnPassant: NoEnPassant, HalfMoveClock: 7},
+		},
+		{
+			name:     "en passant records the captured pawn and previous target",
+			fen:      "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2",
+			mv:       Move{From: Square(36), To: Square(43), Type: Capture | EnPassant},
+			expected: Undo{Captured: Pawn, CastlingRights: NoCastling, EnPassant: Square(43), HalfMoveClock: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			before := *b
+			undo, err := b.MakeMove(tt.mv)
+			if err != nil {
+				t.Errorf("Expected no error, got %v instead", err)
+			}
+			// The keys of the previous position come from the FEN itself
+			tt.expected.Hash, tt.expected.PawnHash = before.Hash, before.PawnHash
+			if undo != tt.expected {
+				t.Errorf("expected undo record %+v, got %+v instead", tt.expected, undo)
+			}
+		})
+	}
+}
+
 func TestBoardToFEN(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -230,7 +515,7 @@ func TestBoardToFEN(t *testing.T) {
 				b.FullMoveCount = 1 // particular case where we have the move counter as zero
 
 			},
-			expected: "8/8/8/8/8/8/8/8 w KQkq - 0 1",
+			expected: "8/8/8/8/8/8/8/8 w - - 0 1",
 		},
 		{
 			name: "initial position",
@@ -249,7 +534,7 @@ func TestBoardToFEN(t *testing.T) {
 					Type: Normal,
 				})
 			},
-			expected: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+			expected: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
 		},
 		{
 			name: "complex middle game position",
@@ -262,7 +547,7 @@ func TestBoardToFEN(t *testing.T) {
 				b.FullMoveCount = 1
 				b.UpdateOccupiedSquares()
 			},
-			expected: "8/1pppp3/q1N5/8/4P3/2P1PP2/8/6K1 w KQkq - 0 1",
+			expected: "8/1pppp3/q1N5/8/4P3/2P1PP2/8/6K1 w - - 0 1",
 		},
 	}
 	for _, tt := range tests {
@@ -278,6 +563,110 @@ func TestBoardToFEN(t *testing.T) {
 	}
 }
 
+func TestPlayMoveUpdatesPositionState(t *testing.T) {
+	tests := []struct {
+		name              string
+		fen               string
+		mv                Move
+		wantCastling      CastlingRights
+		wantEnPassant     Square
+		wantHalfMoveClock int
+	}{
+		{
+			name:              "white king move removes both white castling rights",
+			fen:               "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10",
+			mv:                Move{From: Square(4), To: Square(12), Type: Normal}, // Ke2
+			wantCastling:      BlackKingSide | BlackQueenSide,
+			wantEnPassant:     NoEnPassant,
+			wantHalfMoveClock: 4,
+		},
+		{
+			name:              "black rook move from h8 removes black king side castling",
+			fen:               "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 10",
+			mv:                Move{From: Square(63), To: Square(62), Type: Normal}, // Rg8
+			wantCastling:      WhiteKingSide | WhiteQueenSide | BlackQueenSide,
+			wantEnPassant:     NoEnPassant,
+			wantHalfMoveClock: 1,
+		},
+		{
+			name:              "rook capture on a8 removes castling rights for both rooks involved",
+			fen:               "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 7 10",
+			mv:                Move{From: Square(0), To: Square(56), Type: Capture}, // Rxa8
+			wantCastling:      WhiteKingSide | BlackKingSide,
+			wantEnPassant:     NoEnPassant,
+			wantHalfMoveClock: 0,
+		},
+		{
+			name:              "white double pawn push sets en passant target",
+			fen:               "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			mv:                Move{From: Square(12), To: Square(28), Type: Normal}, // e4
+			wantCastling:      AllCastling,
+			wantEnPassant:     Square(20), // e3
+			wantHalfMoveClock: 0,
+		},
+		{
+			name:              "black double pawn push sets en passant target",
+			fen:               "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			mv:                Move{From: Square(51), To: Square(35), Type: Normal}, // d5
+			wantCastling:      AllCastling,
+			wantEnPassant:     Square(43), // d6
+			wantHalfMoveClock: 0,
+		},
+		{
+			name:              "knight move clears en passant target and increments the halfmove clock",
+			fen:               "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			mv:                Move{From: Square(62), To: Square(45), Type: Normal}, // Nf6
+			wantCastling:      AllCastling,
+			wantEnPassant:     NoEnPassant,
+			wantHalfMoveClock: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+
+			err = b.PlayMove(tt.mv)
+			if err != nil {
+				t.Errorf("Expected no error, got %v instead", err)
+			}
+			if b.CastlingRights != tt.wantCastling {
+				t.Errorf("expected castling rights to be %s, got %s instead", tt.wantCastling, b.CastlingRights)
+			}
+			if b.EnPassant != tt.wantEnPassant {
+				t.Errorf("expected en passant target to be %s, got %s instead", tt.wantEnPassant, b.EnPassant)
+			}
+			if b.HalfMoveClock != tt.wantHalfMoveClock {
+				t.Errorf("expected halfmove clock to be %d, got %d instead", tt.wantHalfMoveClock, b.HalfMoveClock)
+			}
+		})
+	}
+}
+
+func TestBoardToFENRoundTrip(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	}
+	for _, fen := range fens {
+		t.Run(fen, func(t *testing.T) {
+			b, err := ParseFEN(fen)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if result := b.ToFEN(); result != fen {
+				t.Errorf("expected FEN to be %s, got %s instead", fen, result)
+			}
+		})
+	}
+}
+
 func TestPlayMoveError(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -291,16 +680,97 @@ func TestPlayMoveError(t *testing.T) {
 			},
 			move: Move{From: Square(12), To: Square(20), Type: Promotion * 2}, // invalid move type
 		},
+		{
+			name: "castling without the king on its starting square returns error",
+			setup: func(b *Board) {
+				b.SetInitialBoard()
+			},
+			move: Move{From: Square(12), To: Square(14), Type: Castle},
+		},
+		{
+			name: "castling without castling rights returns error",
+			setup: func(b *Board) {
+				b.Pieces[White][King] = Bitboard(1 << 4)
+				b.Pieces[White][Rook] = Bitboard(1 << 7)
+				b.UpdateOccupiedSquares()
+			},
+			move: Move{From: Square(4), To: Square(6), Type: Castle},
+		},
+		{
+			name: "en passant without an en passant target returns error",
+			setup: func(b *Board) {
+				b.Pieces[White][Pawn] = Bitboard(1 << 36)
+				b.Pieces[Black][Pawn] = Bitboard(1 << 35)
+				b.UpdateOccupiedSquares()
+			},
+			move: Move{From: Square(36), To: Square(43), Type: Capture | EnPassant},
+		},
+		{
+			name: "undefined flag combination returns error",
+			setup: func(b *Board) {
+				b.SetInitialBoard()
+			},
+			move: Move{From: Square(12), To: Square(28), Type: Castle | Promotion},
+		},
+		{
+			name: "promotion to a king returns error",
+			setup: func(b *Board) {
+				b.Pieces[White][Pawn] = Bitboard(1 << 52)
+				b.UpdateOccupiedSquares()
+			},
+			move: Move{From: Square(52), To: Square(60), Type: Promotion, Promotion: King},
+		},
+		{
+			name: "capture promotion with empty target square returns error",
+			setup: func(b *Board) {
+				b.Pieces[White][Pawn] = Bitboard(1 << 52)
+				b.UpdateOccupiedSquares()
+			},
+			move: Move{From: Square(52), To: Square(61), Type: Capture | Promotion, Promotion: Queen},
+		},
+		{
+			name: "normal move onto an own piece returns error",
+			setup: func(b *Board) {
+				b.Pieces[White][King] = Bitboard(1 << 4)
+				b.Pieces[White][Rook] = Bitboard(1 << 12)
+				b.Pieces[Black][King] = Bitboard(1 << 60)
+				b.UpdateOccupiedSquares()
+			},
+			move: Move{From: Square(4), To: Square(12), Type: Normal},
+		},
+		{
+			name: "normal move onto an opponent piece returns error",
+			setup: func(b *Board) {
+				b.Pieces[White][King] = Bitboard(1 << 4)
+				b.Pieces[Black][Rook] = Bitboard(1 << 12)
+				b.Pieces[Black][King] = Bitboard(1 << 60)
+				b.UpdateOccupiedSquares()
+			},
+			move: Move{From: Square(4), To: Square(12), Type: Normal},
+		},
+		{
+			name: "promotion onto an occupied square without capture returns error",
+			setup: func(b *Board) {
+				b.Pieces[White][Pawn] = Bitboard(1 << 52)
+				b.Pieces[Black][Knight] = Bitboard(1 << 60)
+				b.UpdateOccupiedSquares()
+			},
+			move: Move{From: Square(52), To: Square(60), Type: Promotion, Promotion: Queen},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			b := &Board{}
 			tt.setup(b)
+			before := *b
 
 			err := b.PlayMove(tt.move)
 			if err == nil {
 				t.Error("expected error, got nil instead")
 			}
+			if *b != before {
+				t.Errorf("expected the board to be left untouched, got %s instead", b.ToFEN())
+			}
 		})
 	}
 }
@@ -0,0 +1,227 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Chess960 starting positions, numbered 0 to 959 by the standard scheme where 518 is the classic setup
+const (
+	Chess960Positions = 960
+	Chess960Standard  = 518
+)
+
+// knightPairs lists the two of the five empty files, left to right, taken by the knights for each digit
+var knightPairs = [10][2]int{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}
+
+// Chess960BackRank returns the back rank pieces of the Chess960 starting position, from the a to the h file.
+// The number places the light square bishop, then the dark square bishop, the queen and the knights,
+// and the rooks and king fill the last three files with the king between the rooks.
+func Chess960BackRank(id int) ([8]Piece, error) {
+	var rank [8]Piece
+	if id < 0 || id >= Chess960Positions {
+		return rank, fmt.Errorf("invalid Chess960 position %d, want 0 to %d", id, Chess960Positions-1)
+	}
+	n := id
+	rank[2*(n%4)+1] = Bishop // b, d, f or h file, light squares
+	n /= 4
+	rank[2*(n%4)] = Bishop // a, c, e or g file, dark squares
+	n /= 4
+	placeOnEmpty(&rank, Queen, n%6)
+	n /= 6
+	pair := knightPairs[n]
+	// The second knight counts the empty files before the first one was placed
+	placeOnEmpty(&rank, Knight, pair[1])
+	placeOnEmpty(&rank, Knight, pair[0])
+	for _, p := range []Piece{Rook, King, Rook} {
+		placeOnEmpty(&rank, p, 0)
+	}
+	return rank, nil
+}
+
+// placeOnEmpty puts the piece on the nth empty file of the rank, counting from 0
+func placeOnEmpty(rank *[8]Piece, p Piece, n int) {
+	for file := range rank {
+		if rank[file] != Empty {
+			continue
+		}
+		if n == 0 {
+			rank[file] = p
+			return
+		}
+		n--
+	}
+}
+
+// SetChess960Position sets the board to the numbered Chess960 starting position, with full castling rights
+// and Chess960 castling.
+func (b *Board) SetChess960Position(id int) error {
+	backRank, err := Chess960BackRank(id)
+	if err != nil {
+		return err
+	}
+	*b = Board{}
+	b.Pieces[White][Pawn] = Rank2
+	b.Pieces[Black][Pawn] = Rank7
+	rules := newCastlingRules()
+	var rooks []int
+	king := 0
+	for file, p := range backRank {
+		b.Pieces[White][p] = b.Pieces[White][p].Set(Square(file))
+		b.Pieces[Black][p] = b.Pieces[Black][p].Set(Square(56 + file))
+		switch p {
+		case Rook:
+			rooks = append(rooks, file)
+		case King:
+			king = file
+		}
+	}
+	for color := White; color <= Black; color++ {
+		rules.set(color, queenSide, king, rooks[0])
+		rules.set(color, kingSide, king, rooks[1])
+	}
+
+	b.SideToMove = White
+	b.CastlingRights = AllCastling
+	b.EnPassant = NoEnPassant
+	b.FullMoveCount = 1
+	b.Chess960 = true
+	if id != Chess960Standard {
+		b.castling = rules
+	}
+	b.UpdateOccupiedSquares()
+	return nil
+}
+
+// parseCastling reads the castling field of a FEN string, accepting the standard KQkq letters,
+// X-FEN, where KQkq stand for the outermost rook on each side and files name the inner ones,
+// and Shredder-FEN, which names the file of every castling rook. Castling rights for rooks off
+// the standard squares switch the board to Chess960. It returns the index of an invalid character.
+func (b *Board) parseCastling(field string) (int, error) {
+	if field == "-" {
+		return 0, nil
+	}
+	rules := *standardCastling
+	custom := false
+	for i, r := range field {
+		color, rank, backRank := White, 0, Rank1
+		if unicode.IsLower(r) {
+			color, rank, backRank = Black, 7, Rank8
+		}
+		king, hasKing := b.kingSquare(color)
+		hasKing = hasKing && king.RankOf() == rank
+		rooks := b.Pieces[color][Rook] & backRank
+		upper := unicode.ToUpper(r)
+
+		var side, rookFile int
+		switch {
+		case upper == 'K' || upper == 'Q':
+			side = kingSide
+			if upper == 'Q' {
+				side = queenSide
+			}
+			// Rights without king or rook in place keep the standard squares, they can never be used
+			rookFile = -1
+			if hasKing {
+				rookFile = outermostRook(rooks, king.FileOf(), side)
+			}
+			if rookFile < 0 {
+				cm := rules.moves[color][side]
+				king, rookFile = cm.kingFrom, cm.rookFrom.FileOf()
+			}
+		case upper >= 'A' && upper <= 'H':
+			rookFile = int(upper - 'A')
+			if !hasKing {
+				return i, fmt.Errorf("castling right %q without king on the back rank", r)
+			}
+			if !rooks.IsSet(Square(rank*8 + rookFile)) {
+				return i, fmt.Errorf("castling right %q without rook on the %c file", r, filesLbl[rookFile])
+			}
+			side = kingSide
+			if rookFile < king.FileOf() {
+				side = queenSide
+			}
+			custom = true
+		default:
+			return i, fmt.Errorf("unknown castling right %q", r)
+		}
+
+		right := rules.moves[color][side].right
+		if b.CastlingRights&right != 0 {
+			return i, fmt.Errorf("duplicated castling right %q", r)
+		}
+		b.CastlingRights |= right
+		if cm := rules.moves[color][side]; cm.kingFrom != king || cm.rookFrom != Square(rank*8+rookFile) {
+			rules.set(color, side, king.FileOf(), rookFile)
+			custom = true
+		}
+	}
+	if custom {
+		b.Chess960 = true
+		if rules != *standardCastling {
+			b.castling = &rules
+		}
+	}
+	return 0, nil
+}
+
+// outermostRook returns the file of the rook furthest from the king on the side, -1 if there is none
+func outermostRook(rooks Bitboard, kingFile, side int) int {
+	if side == kingSide {
+		for file := 7; file > kingFile; file-- {
+			if rooks&(FileA<<file) != 0 {
+				return file
+			}
+		}
+		return -1
+	}
+	for file := 0; file < kingFile; file++ {
+		if rooks&(FileA<<file) != 0 {
+			return file
+		}
+	}
+	return -1
+}
+
+// castlingFEN writes the castling rights in X-FEN, the standard letters unless an inner rook castles,
+// or in Shredder-FEN naming the rook files. Chess960 rights that X-FEN would write as in standard chess,
+// like those of position 518, name the rook files too so the position is read back as Chess960.
+func (b *Board) castlingFEN(shredder bool) string {
+	if b.castling == nil && !b.Chess960 && !shredder {
+		return b.CastlingRights.String()
+	}
+	var sb strings.Builder
+	custom := false // whether the rights read back as Chess960
+	for color := White; color <= Black; color++ {
+		for side, letter := range "KQ" {
+			cm := b.castlingRules().moves[color][side]
+			if b.CastlingRights&cm.right == 0 {
+				continue
+			}
+			backRank := Rank1
+			if color == Black {
+				backRank = Rank8
+			}
+			r := letter
+			outermost := outermostRook(b.Pieces[color][Rook]&backRank, cm.kingFrom.FileOf(), side)
+			if shredder || outermost >= 0 && outermost != cm.rookFrom.FileOf() {
+				r = unicode.ToUpper(filesLbl[cm.rookFrom.FileOf()])
+				custom = true
+			} else if cm != standardCastling.moves[color][side] {
+				custom = true
+			}
+			if color == Black {
+				r = unicode.ToLower(r)
+			}
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == 0 {
+		return "-"
+	}
+	if b.Chess960 && !custom {
+		return b.castlingFEN(true)
+	}
+	return sb.String()
+}
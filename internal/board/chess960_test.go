@@ -0,0 +1,211 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChess960BackRank(t *testing.T) {
+	tests := []struct {
+		id   int
+		want string
+	}{
+		{0, "BBQNNRKR"},
+		{1, "BQNBNRKR"},
+		{518, "RNBQKBNR"},
+		{959, "RKRNNQBB"},
+	}
+	for _, tt := range tests {
+		rank, err := Chess960BackRank(tt.id)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v instead", err)
+		}
+		var sb strings.Builder
+		for _, p := range rank {
+			sb.WriteString(p.String())
+		}
+		if sb.String() != tt.want {
+			t.Errorf("position %d: expected %s, got %s instead", tt.id, tt.want, sb.String())
+		}
+	}
+
+	// Every number gives a different valid setup
+	seen := map[[8]Piece]bool{}
+	for id := 0; id < Chess960Positions; id++ {
+		rank, _ := Chess960BackRank(id)
+		if seen[rank] {
+			t.Fatalf("position %d repeats an earlier setup", id)
+		}
+		seen[rank] = true
+
+		var bishops, rooks []int
+		king := -1
+		for file, p := range rank {
+			switch p {
+			case Bishop:
+				bishops = append(bishops, file)
+			case Rook:
+				rooks = append(rooks, file)
+			case King:
+				king = file
+			}
+		}
+		if len(bishops) != 2 || bishops[0]%2 == bishops[1]%2 {
+			t.Errorf("position %d: bishops on %v are not on opposite colors", id, bishops)
+		}
+		if len(rooks) != 2 || king < rooks[0] || king > rooks[1] {
+			t.Errorf("position %d: king on %d is not between the rooks on %v", id, king, rooks)
+		}
+	}
+
+	for _, id := range []int{-1, Chess960Positions} {
+		if _, err := Chess960BackRank(id); err == nil {
+			t.Errorf("position %d: expected error, got nil instead", id)
+		}
+	}
+}
+
+func TestSetChess960Position(t *testing.T) {
+	tests := []struct {
+		id       int
+		fen      string
+		shredder string
+	}{
+		{518, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1"},
+		{0, "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1", "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w HFhf - 0 1"},
+		{959, "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB w KQkq - 0 1", "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB w CAca - 0 1"},
+	}
+	for _, tt := range tests {
+		var b Board
+		if err := b.SetChess960Position(tt.id); err != nil {
+			t.Fatalf("Expected no error, got %v instead", err)
+		}
+		if !b.Chess960 {
+			t.Errorf("position %d: expected Chess960 castling", tt.id)
+		}
+		if got := b.ToFEN(); got != tt.fen {
+			t.Errorf("position %d: expected FEN %s, got %s instead", tt.id, tt.fen, got)
+		}
+		if got := b.ToShredderFEN(); got != tt.shredder {
+			t.Errorf("position %d: expected Shredder-FEN %s, got %s instead", tt.id, tt.shredder, got)
+		}
+		if err := b.Validate(); err != nil {
+			t.Errorf("position %d: expected a valid board, got %v instead", tt.id, err)
+		}
+		// The FEN reads back as the same Chess960 position, the classic setup included
+		back, err := ParseFEN(b.ToFEN())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v instead", err)
+		}
+		if !back.Chess960 || back.ToShredderFEN() != tt.shredder {
+			t.Errorf("position %d: expected Chess960 %s read back, got %s (%v) instead",
+				tt.id, tt.shredder, back.ToShredderFEN(), back.Chess960)
+		}
+	}
+
+	var b Board
+	if err := b.SetChess960Position(Chess960Positions); err == nil {
+		t.Errorf("expected error, got nil instead")
+	}
+}
+
+func TestParseFENChess960Castling(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		xfen     string
+		shredder string
+		chess960 bool
+	}{
+		{
+			"standard letters", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1", false,
+		},
+		{
+			"shredder letters on standard squares", "r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1",
+			"r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1", "r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1", true,
+		},
+		{
+			"x-fen outermost rooks", "1r2k1r1/8/8/8/8/8/8/1R2K1R1 w KQkq - 0 1",
+			"1r2k1r1/8/8/8/8/8/8/1R2K1R1 w KQkq - 0 1", "1r2k1r1/8/8/8/8/8/8/1R2K1R1 w GBgb - 0 1", true,
+		},
+		{
+			"x-fen inner rook", "4k3/8/8/8/8/8/8/1R1K1RR1 w Fk - 0 1",
+			"4k3/8/8/8/8/8/8/1R1K1RR1 w Fk - 0 1", "4k3/8/8/8/8/8/8/1R1K1RR1 w Fh - 0 1", true,
+		},
+		{
+			"rights without rooks keep the standard squares", "4k3/8/8/8/8/8/8/6K1 w Kq - 0 1",
+			"4k3/8/8/8/8/8/8/6K1 w Kq - 0 1", "4k3/8/8/8/8/8/8/6K1 w Ha - 0 1", false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if b.Chess960 != tt.chess960 {
+				t.Errorf("expected Chess960 %v, got %v instead", tt.chess960, b.Chess960)
+			}
+			if got := b.ToFEN(); got != tt.xfen {
+				t.Errorf("expected FEN %s, got %s instead", tt.xfen, got)
+			}
+			if got := b.ToShredderFEN(); got != tt.shredder {
+				t.Errorf("expected Shredder-FEN %s, got %s instead", tt.shredder, got)
+			}
+		})
+	}
+}
+
+func TestChess960Castling(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		uci  string
+		san  string
+		want string
+	}{
+		{"king and rook swap sides", "4k3/8/8/8/8/8/8/1RK4R w HB - 0 1", "c1b1", "O-O-O", "4k3/8/8/8/8/8/8/2KR3R b - - 1 1"},
+		{"king stays in place", "4k3/8/8/8/8/8/8/R5KR w HA - 0 1", "g1h1", "O-O", "4k3/8/8/8/8/8/8/R4RK1 b - - 1 1"},
+		{"rook lands on the king square", "4k3/8/8/8/8/8/8/R4KR1 w GA - 0 1", "f1g1", "O-O", "4k3/8/8/8/8/8/8/R4RK1 b - - 1 1"},
+		{"king lands on the rook square", "4k3/8/8/8/8/8/8/2RK3R w HC - 0 1", "d1c1", "O-O-O", "4k3/8/8/8/8/8/8/2KR3R b - - 1 1"},
+		{"black castling", "r3k1r1/8/8/8/8/8/8/4K3 b ga - 0 1", "e8g8", "O-O", "r4rk1/8/8/8/8/8/8/4K3 w - - 1 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			m, err := b.ParseUCIMove(tt.uci)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if !m.Type.Has(Castle) {
+				t.Fatalf("expected a castling move, got %v (%v) instead", m, m.Type)
+			}
+			if san := b.MoveToSAN(m); san != tt.san {
+				t.Errorf("expected SAN %s, got %s instead", tt.san, san)
+			}
+			if parsed, err := b.ParseSAN(tt.san); err != nil || parsed != m {
+				t.Errorf("expected ParseSAN to return %v, got %v (%v) instead", m, parsed, err)
+			}
+
+			before := *b
+			undo, err := b.MakeMove(m)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if got := b.ToFEN(); got != tt.want {
+				t.Errorf("expected %s, got %s instead", tt.want, got)
+			}
+			if err := b.Validate(); err != nil {
+				t.Errorf("expected a valid board, got %v instead", err)
+			}
+			b.UnmakeMove(m, undo)
+			if *b != before {
+				t.Errorf("expected UnmakeMove to restore %s, got %s instead", tt.fen, b.ToFEN())
+			}
+		})
+	}
+}
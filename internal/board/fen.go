@@ -0,0 +1,199 @@
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FENField identifies one of the six space separated fields of a FEN string
+type FENField uint8
+
+const (
+	FieldPlacement FENField = iota // piece placement, rank 8 to rank 1
+	FieldSideToMove
+	FieldCastling
+	FieldEnPassant
+	FieldHalfMoveClock
+	FieldFullMoveNumber
+)
+
+func (f FENField) String() string {
+	switch f {
+	case FieldPlacement:
+		return "piece placement"
+	case FieldSideToMove:
+		return "side to move"
+	case FieldCastling:
+		return "castling rights"
+	case FieldEnPassant:
+		return "en passant target"
+	case FieldHalfMoveClock:
+		return "halfmove clock"
+	case FieldFullMoveNumber:
+		return "fullmove number"
+	}
+	return "unknown field"
+}
+
+// FENError reports a malformed FEN string, the field where parsing failed
+// and the byte offset of the offending character inside the full string.
+type FENError struct {
+	FEN    string   // FEN string being parsed
+	Field  FENField // field that failed to parse
+	Offset int      // byte offset inside FEN where the problem was found
+	Msg    string   // description of the problem
+}
+
+func (e *FENError) Error() string {
+	return fmt.Sprintf("invalid FEN %s at offset %d: %s", e.Field, e.Offset, e.Msg)
+}
+
+// Color names for error messages
+var colorNames = [2]string{"white", "black"}
+
+// ParseFEN builds a Board from a position in Forsyth-Edwards Notation.
+// The halfmove clock and fullmove number may be omitted, defaulting to 0 and 1.
+// Castling rights may also be written in X-FEN or Shredder-FEN for Chess960 positions.
+// The position needs a single king for each side and no pawns on the first or last rank.
+func ParseFEN(fen string) (*Board, error) {
+	b := &Board{}
+	fields := strings.Fields(fen)
+	if len(fields) != 4 && len(fields) != 6 {
+		return nil, &FENError{FEN: fen, Field: FieldPlacement, Offset: 0, Msg: fmt.Sprintf("expected 4 or 6 fields, got %d", len(fields))}
+	}
+
+	// Track the offset of each field so errors can point inside the original string
+	offsets := make([]int, len(fields))
+	pos := 0
+	for i, f := range fields {
+		pos += strings.Index(fen[pos:], f)
+		offsets[i] = pos
+		pos += len(f)
+	}
+	fail := func(field FENField, at int, format string, args ...any) error {
+		return &FENError{FEN: fen, Field: field, Offset: offsets[field] + at, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	// Piece placement, traverse ranks in reverse (8 to 1)
+	rank, file := 7, 0
+	for i, r := range fields[FieldPlacement] {
+		switch {
+		case r == '/':
+			if file != 8 {
+				return nil, fail(FieldPlacement, i, "rank %d describes %d files, want 8", rank+1, file)
+			}
+			if rank == 0 {
+				return nil, fail(FieldPlacement, i, "more than 8 ranks")
+			}
+			rank--
+			file = 0
+		case r >= '1' && r <= '8':
+			file += int(r - '0')
+			if file > 8 {
+				return nil, fail(FieldPlacement, i, "rank %d describes more than 8 files", rank+1)
+			}
+		default:
+			color, piece := pieceFromRune(r)
+			if piece == Empty {
+				return nil, fail(FieldPlacement, i, "unknown piece %q", r)
+			}
+			if file > 7 {
+				return nil, fail(FieldPlacement, i, "rank %d describes more than 8 files", rank+1)
+			}
+			if piece == Pawn && (rank == 0 || rank == 7) {
+				return nil, fail(FieldPlacement, i, "pawn on rank %d", rank+1)
+			}
+			if piece == King && b.Pieces[color][King] != 0 {
+				return nil, fail(FieldPlacement, i, "more than one %s king", colorNames[color])
+			}
+			sq := Square(rank*8 + file)
+			b.Pieces[color][piece] = b.Pieces[color][piece].Set(sq)
+			file++
+		}
+	}
+	if rank != 0 || file != 8 {
+		return nil, fail(FieldPlacement, len(fields[FieldPlacement]), "placement ends at rank %d file %d, want 8 complete ranks", rank+1, file)
+	}
+	for color := White; color <= Black; color++ {
+		if b.Pieces[color][King] == 0 {
+			return nil, fail(FieldPlacement, len(fields[FieldPlacement]), "no %s king", colorNames[color])
+		}
+	}
+	b.UpdateOccupiedSquares()
+
+	// Side to move
+	switch fields[FieldSideToMove] {
+	case "w":
+		b.SideToMove = White
+	case "b":
+		b.SideToMove = Black
+	default:
+		return nil, fail(FieldSideToMove, 0, "expected 'w' or 'b', got %q", fields[FieldSideToMove])
+	}
+
+	// Castling rights
+	if at, err := b.parseCastling(fields[FieldCastling]); err != nil {
+		return nil, fail(FieldCastling, at, "%v", err)
+	}
+
+	// En passant target square
+	if fields[FieldEnPassant] != "-" {
+		sq, err := ParseSquare(fields[FieldEnPassant])
+		if err != nil {
+			return nil, fail(FieldEnPassant, 0, "%v", err)
+		}
+		// The target is behind a pawn that just moved two squares
+		wantRank := 5
+		if b.SideToMove == Black {
+			wantRank = 2
+		}
+		if sq.RankOf() != wantRank {
+			return nil, fail(FieldEnPassant, 1, "square %v is not on rank %d", sq, wantRank+1)
+		}
+		b.EnPassant = sq
+	}
+
+	// Move counters
+	b.FullMoveCount = 1
+	if len(fields) == 6 {
+		hm, err := strconv.Atoi(fields[FieldHalfMoveClock])
+		if err != nil || hm < 0 {
+			return nil, fail(FieldHalfMoveClock, 0, "expected a non negative integer, got %q", fields[FieldHalfMoveClock])
+		}
+		fm, err := strconv.Atoi(fields[FieldFullMoveNumber])
+		if err != nil || fm < 1 {
+			return nil, fail(FieldFullMoveNumber, 0, "expected a positive integer, got %q", fields[FieldFullMoveNumber])
+		}
+		b.HalfMoveClock = hm
+		b.FullMoveCount = fm
+	}
+
+	b.UpdateHash()
+	return b, nil
+}
+
+// pieceFromRune converts a FEN piece letter into its color and type,
+// uppercase letters are White pieces and lowercase are Black.
+func pieceFromRune(r rune) (Color, Piece) {
+	color := White
+	if unicode.IsLower(r) {
+		color = Black
+	}
+	switch unicode.ToUpper(r) {
+	case 'P':
+		return color, Pawn
+	case 'N':
+		return color, Knight
+	case 'B':
+		return color, Bishop
+	case 'R':
+		return color, Rook
+	case 'Q':
+		return color, Queen
+	case 'K':
+		return color, King
+	}
+	return None, Empty
+}
@@ -0,0 +1,239 @@
+package board
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFEN(t *testing.T) {
+	tests := []struct {
+		name          string
+		fen           string
+		expectedBoard func(*Board)
+	}{
+		{
+			name: "initial position",
+			fen:  "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			expectedBoard: func(b *Board) {
+				b.SetInitialBoard()
+			},
+		},
+		{
+			name: "after 1. e4 with en passant target",
+			fen:  "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			expectedBoard: func(b *Board) {
+				b.SetInitialBoard()
+				b.Pieces[White][Pawn] = b.Pieces[White][Pawn].Clear(Square(12)).Set(Square(28))
+				b.UpdateOccupiedSquares()
+				b.SideToMove = Black
+				b.EnPassant = Square(20) // e3
+			},
+		},
+		{
+			name: "middle game position with partial castling rights and clocks",
+			fen:  "k7/1pppp3/q1N5/8/4P3/2P1PP2/8/6K1 w Kq - 12 34",
+			expectedBoard: func(b *Board) {
+				b.Pieces[White][Pawn] = Bitboard(1<<18 | 1<<20 | 1<<21 | 1<<28) // c3, e3, f3, e4
+				b.Pieces[White][Knight] = Bitboard(1 << 42)                     // Nc6
+				b.Pieces[White][King] = Bitboard(1 << 6)                        // Kg1
+				b.Pieces[Black][Pawn] = Bitboard(1<<49 | 1<<50 | 1<<51 | 1<<52) // b7, c7, d7, e7
+				b.Pieces[Black][Queen] = Bitboard(1 << 40)                      // Qa6
+				b.Pieces[Black][King] = Bitboard(1 << 56)                       // Ka8
+				b.UpdateOccupiedSquares()
+				b.CastlingRights = WhiteKingSide | BlackQueenSide
+				b.HalfMoveClock = 12
+				b.FullMoveCount = 34
+			},
+		},
+		{
+			name: "missing move counters default to 0 and 1",
+			fen:  "4k3/8/8/8/8/8/8/4K3 b - -",
+			expectedBoard: func(b *Board) {
+				b.Pieces[White][King] = Bitboard(1 << 4)  // Ke1
+				b.Pieces[Black][King] = Bitboard(1 << 60) // Ke8
+				b.UpdateOccupiedSquares()
+				b.SideToMove = Black
+				b.FullMoveCount = 1
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			expectedBoard := &Board{}
+			tt.expectedBoard(expectedBoard)
+
+			if !b.isEqualBoard(*expectedBoard) {
+				t.Errorf("resulting board does not match desired output")
+			}
+			if b.OccupiedByColor != expectedBoard.OccupiedByColor {
+				t.Errorf("expected occupied squares by color to be %v, got %v instead", expectedBoard.OccupiedByColor, b.OccupiedByColor)
+			}
+			if b.SideToMove != expectedBoard.SideToMove {
+				t.Errorf("expected side to move to be %v, got %v instead", expectedBoard.SideToMove, b.SideToMove)
+			}
+			if b.CastlingRights != expectedBoard.CastlingRights {
+				t.Errorf("expected castling rights to be %s, got %s instead", expectedBoard.CastlingRights, b.CastlingRights)
+			}
+			if b.EnPassant != expectedBoard.EnPassant {
+				t.Errorf("expected en passant target to be %s, got %s instead", expectedBoard.EnPassant, b.EnPassant)
+			}
+			if b.HalfMoveClock != expectedBoard.HalfMoveClock {
+				t.Errorf("expected halfmove clock to be %d, got %d instead", expectedBoard.HalfMoveClock, b.HalfMoveClock)
+			}
+			if b.FullMoveCount != expectedBoard.FullMoveCount {
+				t.Errorf("expected fullmove number to be %d, got %d instead", expectedBoard.FullMoveCount, b.FullMoveCount)
+			}
+		})
+	}
+}
+
+func TestParseFENError(t *testing.T) {
+	tests := []struct {
+		name       string
+		fen        string
+		wantField  FENField
+		wantOffset int
+	}{
+		{
+			name:       "empty string",
+			fen:        "",
+			wantField:  FieldPlacement,
+			wantOffset: 0,
+		},
+		{
+			name:       "unknown piece letter",
+			fen:        "rnbqkbnr/ppppxppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 13,
+		},
+		{
+			name:       "rank with too many files",
+			fen:        "rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 18,
+		},
+		{
+			name:       "rank with too few files",
+			fen:        "rnbqkbnr/pppppppp/7/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 19,
+		},
+		{
+			name:       "missing ranks",
+			fen:        "rnbqkbnr/pppppppp/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 41,
+		},
+		{
+			name:       "no kings",
+			fen:        "8/8/8/8/8/8/8/8 w - - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 15,
+		},
+		{
+			name:       "missing black king",
+			fen:        "8/8/8/8/8/8/8/R3K3 w - - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 18,
+		},
+		{
+			name:       "two white kings",
+			fen:        "4k3/8/8/8/8/8/8/K3K3 w - - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 18,
+		},
+		{
+			name:       "pawn on the first rank",
+			fen:        "4k3/8/8/8/8/8/8/P3K3 w - - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 16,
+		},
+		{
+			name:       "pawn on the last rank",
+			fen:        "4k2p/8/8/8/8/8/8/4K3 w - - 0 1",
+			wantField:  FieldPlacement,
+			wantOffset: 3,
+		},
+		{
+			name:       "invalid side to move",
+			fen:        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",
+			wantField:  FieldSideToMove,
+			wantOffset: 44,
+		},
+		{
+			name:       "unknown castling right",
+			fen:        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQxq - 0 1",
+			wantField:  FieldCastling,
+			wantOffset: 48,
+		},
+		{
+			name:       "duplicated castling right",
+			fen:        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KKq - 0 1",
+			wantField:  FieldCastling,
+			wantOffset: 47,
+		},
+		{
+			name:       "en passant square outside the board",
+			fen:        "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e9 0 1",
+			wantField:  FieldEnPassant,
+			wantOffset: 53,
+		},
+		{
+			name:       "en passant square on the wrong rank",
+			fen:        "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e6 0 1",
+			wantField:  FieldEnPassant,
+			wantOffset: 54,
+		},
+		{
+			name:       "negative halfmove clock",
+			fen:        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1",
+			wantField:  FieldHalfMoveClock,
+			wantOffset: 53,
+		},
+		{
+			name:       "zero fullmove number",
+			fen:        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0",
+			wantField:  FieldFullMoveNumber,
+			wantOffset: 55,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err == nil {
+				t.Fatalf("expected error, got nil instead with board %v", b.ToFEN())
+			}
+			var fenErr *FENError
+			if !errors.As(err, &fenErr) {
+				t.Fatalf("expected a *FENError, got %T instead", err)
+			}
+			if fenErr.Field != tt.wantField {
+				t.Errorf("expected error on field %s, got %s instead", tt.wantField, fenErr.Field)
+			}
+			if fenErr.Offset != tt.wantOffset {
+				t.Errorf("expected error at offset %d, got %d instead", tt.wantOffset, fenErr.Offset)
+			}
+		})
+	}
+}
+
+func TestParseSquare(t *testing.T) {
+	for sq := Square(0); sq < 64; sq++ {
+		result, err := ParseSquare(sq.String())
+		if err != nil {
+			t.Errorf("ParseSquare(%q) returned unexpected error %v", sq, err)
+		}
+		if result != sq {
+			t.Errorf("ParseSquare(%q) = %d; want %d", sq, result, sq)
+		}
+	}
+	for _, s := range []string{"", "e", "i1", "a0", "a9", "e44"} {
+		if _, err := ParseSquare(s); err == nil {
+			t.Errorf("ParseSquare(%q) expected error, got nil instead", s)
+		}
+	}
+}
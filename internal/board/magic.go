@@ -0,0 +1,138 @@
+package board
+
+import (
+	"fmt"
+
+	"github.com/deadpyxel/cheesy/internal/utils"
+)
+
+// magic holds what is needed to look up the attacks of a sliding piece on one square.
+// Relevant blockers are multiplied by a magic number, the top bits of the product index the attack set.
+type magic struct {
+	mask    Bitboard   // squares whose occupancy changes the attacks, board edges excluded
+	number  uint64     // magic multiplier mapping each blocker subset into a unique index
+	shift   uint8      // 64 minus the number of relevant squares
+	attacks []Bitboard // attack sets indexed by the hashed blockers
+}
+
+func (m *magic) index(occupied Bitboard) uint64 {
+	return (uint64(occupied&m.mask) * m.number) >> m.shift
+}
+
+// Magic attack lookup tables for each square
+var (
+	rookTable   [64]magic
+	bishopTable [64]magic
+)
+
+// Magic numbers found with a brute force search of sparse random candidates
+var rookMagicNumbers = [64]uint64{
+	0x0080068051E04000, 0x0040001000402000, 0x0080100020008008, 0x4E000A0010208440,
+	0x4200040802002010, 0x0100010008020400, 0x9080608019000600, 0x8100020080204100,
+	0x4103800480400020, 0x8015004004802100, 0x000200108A002040, 0x0801000821001000,
+	0x0015000500080070, 0x0120800400800200, 0x0109000432001100, 0x020080055B000080,
+	0x0080004000402002, 0x5260848020004008, 0x2402020014402080, 0x3000808010000802,
+	0x0304018004810800, 0x0000808004000200, 0x0002040001500248, 0x0012020000408401,
+	0x8440008080004020, 0x0804200840100040, 0x0820008080201000, 0x2080100100082100,
+	0x0001000500100800, 0x00A1000900028400, 0x0100100400C80102, 0x000001120000A044,
+	0x800080C004800620, 0x4040081000202000, 0x0D08802008801000, 0x1000800800801004,
+	0x1004000801010010, 0x0402800400800200, 0x0004080204008110, 0x0000404082000401,
+	0x00C0118861408000, 0x1100220081020048, 0x09A0430420050010, 0x0000082200420010,
+	0x2110080004008080, 0x2004201040680104, 0x1106001451820008, 0x0002224104820014,
+	0x00800C8044210500, 0x02A0200040100040, 0x040100A0001E4100, 0x00204023108A0200,
+	0x2400080080040080, 0x1289008400020900, 0x0002088250010400, 0x0001006084010200,
+	0x0001023480002141, 0x0006400021810015, 0x8400100840200101, 0x40003000A1000825,
+	0x1002011008200402, 0x100D000400080201, 0x0020048806102904, 0x8401000020804201,
+}
+var bishopMagicNumbers = [64]uint64{
+	0x4C40240122060016, 0x8048110404004A80, 0x8004440410414020, 0x021C410060405000,
+	0x80CD1040D0480812, 0x0002021104000082, 0x08440082A8200001, 0x00202A0800841002,
+	0x0200C40810842088, 0x60C0081000C08901, 0x00A3D0040042510C, 0x1C00110400808541,
+	0x0400820211084005, 0x0000008860080800, 0x002002020202C000, 0x0400344E08040A81,
+	0x812800102098A080, 0x00202010823A2040, 0x4086400800830201, 0x5008012A22004000,
+	0x0004801C00A00000, 0x0000400200505400, 0x0480408401080820, 0x8000400029082824,
+	0x0008880804501000, 0x0001600048084100, 0x0108220624040400, 0x0008080000820002,
+	0xC804040010410041, 0x01080A0040208400, 0x2018030480A88800, 0x4040410020410810,
+	0x1108044010100210, 0x084A100400029800, 0x0801080100820C00, 0x8010400808108200,
+	0x0084008400020500, 0x0002004200290481, 0x0010150200032090, 0x8404042220404102,
+	0x0302080308004008, 0x1200420820000408, 0x0802002024200800, 0x4020824208000084,
+	0x000002020C008200, 0x2C40208081000882, 0x2082223441000401, 0x8804080081101020,
+	0x4401011002220808, 0x81020C4202100000, 0x4005004404040308, 0x0820400C42020001,
+	0x0020206421820010, 0x0150401001424008, 0x02A20242020C0608, 0x5020110109011200,
+	0x2050840108410401, 0x0100090880842108, 0x220008960142187A, 0x1111028880208820,
+	0x4400200042028200, 0x4400010802084206, 0x0000400242040100, 0x0002201104010944,
+}
+
+func init() {
+	initMagics(&rookTable, rookMagicNumbers, RookDirections[:])
+	initMagics(&bishopTable, bishopMagicNumbers, BishopDirections[:])
+}
+
+// initMagics fills the lookup tables, every blocker subset of each square is enumerated
+// and checked against the slow ray walk so a bad magic number fails loudly at startup.
+func initMagics(table *[64]magic, numbers [64]uint64, directions []int) {
+	for sq := Square(0); sq < 64; sq++ {
+		// Pieces on the last square of a ray never block anything
+		edges := ((Rank1 | Rank8) &^ (Rank1 << (8 * sq.RankOf()))) | ((FileA | FileH) &^ (FileA << sq.FileOf()))
+		m := &table[sq]
+		m.mask = slidingAttacks(sq, directions, 0) &^ edges
+		m.number = numbers[sq]
+		m.shift = uint8(64 - m.mask.Count())
+		m.attacks = make([]Bitboard, 1<<(64-m.shift))
+
+		// Carry-Rippler trick to enumerate all subsets of the mask
+		blockers := Bitboard(0)
+		for {
+			attacks := slidingAttacks(sq, directions, blockers)
+			idx := m.index(blockers)
+			if m.attacks[idx] != 0 && m.attacks[idx] != attacks {
+				panic(fmt.Sprintf("magic number collision on square %v", sq))
+			}
+			m.attacks[idx] = attacks
+			blockers = (blockers - m.mask) & m.mask
+			if blockers == 0 {
+				break
+			}
+		}
+	}
+}
+
+// slidingAttacks walks each ray square by square until it hits a blocker or the board edge.
+// It is only used to build the magic tables, lookups go through RookAttacks and BishopAttacks.
+func slidingAttacks(sq Square, directions []int, blockers Bitboard) Bitboard {
+	var attacks Bitboard
+	for _, dir := range directions {
+		lastFile := sq.FileOf()
+		for toSq := int(sq) + dir; !isOutOfBoard(toSq); toSq += dir {
+			tgtSq := Square(toSq)
+			toFile := tgtSq.FileOf()
+			// Check if this step would wrap around board edges
+			if utils.Abs(toFile-lastFile) > 1 {
+				break
+			}
+			attacks = attacks.Set(tgtSq)
+			if blockers.IsSet(tgtSq) {
+				break
+			}
+			lastFile = toFile
+		}
+	}
+	return attacks
+}
+
+// RookAttacks returns the squares attacked by a rook on sq given the occupied squares.
+func RookAttacks(sq Square, occupied Bitboard) Bitboard {
+	m := &rookTable[sq]
+	return m.attacks[m.index(occupied)]
+}
+
+// BishopAttacks returns the squares attacked by a bishop on sq given the occupied squares.
+func BishopAttacks(sq Square, occupied Bitboard) Bitboard {
+	m := &bishopTable[sq]
+	return m.attacks[m.index(occupied)]
+}
+
+// QueenAttacks returns the squares attacked by a queen on sq given the occupied squares.
+func QueenAttacks(sq Square, occupied Bitboard) Bitboard {
+	return RookAttacks(sq, occupied) | BishopAttacks(sq, occupied)
+}
@@ -0,0 +1,56 @@
+package board
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSlidingAttacksMatchRayWalk(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for sq := Square(0); sq < 64; sq++ {
+		for i := 0; i < 200; i++ {
+			// Sparse random occupancy, similar to real positions
+			occupied := Bitboard(rng.Uint64() & rng.Uint64() & rng.Uint64())
+
+			if got, want := RookAttacks(sq, occupied), slidingAttacks(sq, RookDirections[:], occupied); got != want {
+				t.Fatalf("RookAttacks(%s) mismatch with occupancy\n%s\n got \n%s\n want \n%s\n", sq, occupied, got, want)
+			}
+			if got, want := BishopAttacks(sq, occupied), slidingAttacks(sq, BishopDirections[:], occupied); got != want {
+				t.Fatalf("BishopAttacks(%s) mismatch with occupancy\n%s\n got \n%s\n want \n%s\n", sq, occupied, got, want)
+			}
+			if got, want := QueenAttacks(sq, occupied), slidingAttacks(sq, QueenDirections[:], occupied); got != want {
+				t.Fatalf("QueenAttacks(%s) mismatch with occupancy\n%s\n got \n%s\n want \n%s\n", sq, occupied, got, want)
+			}
+		}
+	}
+}
+
+func TestRookAttacks(t *testing.T) {
+	tests := []struct {
+		name     string
+		sq       Square
+		occupied Bitboard
+		expected Bitboard
+	}{
+		{
+			name:     "rook on a1 of empty board sees whole file and rank",
+			sq:       Square(0),
+			occupied: 0,
+			expected: (FileA | Rank1) &^ (FileA & Rank1),
+		},
+		{
+			name:     "rook on e4 stops at blockers, including them",
+			sq:       Square(28),
+			occupied: Bitboard(1<<36 | 1<<26), // e5 and c4
+			expected: Bitboard(1<<36|1<<27|1<<26) | (Rank4 & (FileF | FileG | FileH)) | (FileE & (Rank1 | Rank2 | Rank3)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RookAttacks(tt.sq, tt.occupied)
+			if result != tt.expected {
+				t.Errorf("expected attacks:\n%s\n got \n%s\n", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package board
+
+// Squares of each color, to tell bishops apart
+const (
+	LightSquares Bitboard = 0x55AA55AA55AA55AA
+	DarkSquares  Bitboard = ^LightSquares
+)
+
+// IsInsufficientMaterial checks if neither side has the material to checkmate in any way:
+// bare kings, a single knight or bishop against a bare king, or only bishops all on the same square color.
+func (b *Board) IsInsufficientMaterial() bool {
+	for color := White; color <= Black; color++ {
+		if b.Pieces[color][Pawn]|b.Pieces[color][Rook]|b.Pieces[color][Queen] != 0 {
+			return false
+		}
+	}
+	knights := b.Pieces[White][Knight] | b.Pieces[Black][Knight]
+	bishops := b.Pieces[White][Bishop] | b.Pieces[Black][Bishop]
+	switch {
+	case knights == 0:
+		// Bishops on a single square color can never attack the squares around a king on the other
+		return bishops&LightSquares == 0 || bishops&DarkSquares == 0
+	case bishops == 0:
+		return knights.Count() == 1
+	}
+	return false
+}
+
+// HasMatingMaterial checks if the player could checkmate with some series of legal moves, as needed
+// to decide a flag fall. A lone knight only mates with help from enemy pieces other than queens,
+// which cannot block without also defending, and bishops on a single square color need blockers
+// on the other color, so with a bare enemy king neither can ever mate.
+func (b *Board) HasMatingMaterial(color Color) bool {
+	own := &b.Pieces[color]
+	if own[Pawn]|own[Rook]|own[Queen] != 0 {
+		return true
+	}
+	opponent := &b.Pieces[color^1]
+	opponentPieces := b.OccupiedByColor[color^1] &^ opponent[King]
+	switch {
+	case own[Knight] == 0 && own[Bishop] == 0:
+		return false
+	case own[Bishop] == 0 && own[Knight].Count() == 1:
+		return opponentPieces&^opponent[Queen] != 0
+	case own[Knight] == 0 && (own[Bishop]&LightSquares == 0 || own[Bishop]&DarkSquares == 0):
+		return opponentPieces != 0
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package board
+
+import "testing"
+
+func TestIsInsufficientMaterial(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		{"bare kings", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", true},
+		{"king and knight against king", "4k3/8/8/8/8/8/8/4KN2 w - - 0 1", true},
+		{"king and bishop against king", "4k3/8/8/8/8/8/8/4KB2 w - - 0 1", true},
+		{"bishops on the same square color", "4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", true},
+		{"bishops on different square colors", "4k1b1/8/8/8/8/8/8/2B1K3 w - - 0 1", false},
+		{"two knights", "4k3/8/8/8/8/8/8/3NKN2 w - - 0 1", false},
+		{"knight against knight", "4kn2/8/8/8/8/8/8/4KN2 w - - 0 1", false},
+		{"knight and bishop", "4k3/8/8/8/8/8/8/3NKB2 w - - 0 1", false},
+		{"single pawn", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", false},
+		{"single rook", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", false},
+		{"initial position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			if got := b.IsInsufficientMaterial(); got != tt.want {
+				t.Errorf("expected %v, got %v instead", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHasMatingMaterial(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		white bool
+		black bool
+	}{
+		{"bare kings", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", false, false},
+		{"knight against bare king", "4k3/8/8/8/8/8/8/4KN2 w - - 0 1", false, false},
+		{"knight against queen", "4k3/8/8/8/8/8/8/q3KN2 w - - 0 1", false, true},
+		{"knight against pawn", "4k3/7p/8/8/8/8/8/4KN2 w - - 0 1", true, true},
+		{"bishop against bare king", "4k3/8/8/8/8/8/8/4KB2 w - - 0 1", false, false},
+		{"bishop against rook", "4k2r/8/8/8/8/8/8/4KB2 w - - 0 1", true, true},
+		{"bishops on both colors", "4k3/8/8/8/8/8/8/2B1KB2 w - - 0 1", true, false},
+		{"bishop and knight", "4k3/8/8/8/8/8/8/3NKB2 w - - 0 1", true, false},
+		{"two knights", "4k3/8/8/8/8/8/8/3NKN2 w - - 0 1", true, false},
+		{"single pawn", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			if got := b.HasMatingMaterial(White); got != tt.white {
+				t.Errorf("expected White mating material %v, got %v instead", tt.white, got)
+			}
+			if got := b.HasMatingMaterial(Black); got != tt.black {
+				t.Errorf("expected Black mating material %v, got %v instead", tt.black, got)
+			}
+		})
+	}
+}
@@ -2,21 +2,43 @@ package board
 
 import (
 	"fmt"
-
-	"github.com/deadpyxel/cheesy/internal/utils"
+	"strings"
 )
 
 type MoveType uint8
 
-// Types of chess movements
+// Flags describing chess movements, combined with bitwise OR (e.g. Capture | Promotion)
 const (
-	Normal    MoveType = iota // move a piece
-	Capture                   // move to a square and capture opponent piece
-	EnPassant                 // special pawn capture
-	Castle                    // special movement between rook and king
-	Promotion                 // special move for pawn, changing into another piece
+	Capture   MoveType = 1 << iota // move to a square and capture opponent piece
+	EnPassant                      // special pawn capture, always combined with Capture
+	Castle                         // special movement between rook and king
+	Promotion                      // special move for pawn, changing into another piece
+
+	Normal MoveType = 0 // move a piece
 )
 
+// Has checks if all the given flags are set on the move type
+func (mt MoveType) Has(flags MoveType) bool {
+	return mt&flags == flags
+}
+
+func (mt MoveType) String() string {
+	if mt == Normal {
+		return "Normal"
+	}
+	names := []string{"Capture", "EnPassant", "Castle", "Promotion"}
+	var result []string
+	for i, name := range names {
+		if mt.Has(1 << i) {
+			result = append(result, name)
+		}
+	}
+	if rest := mt >> len(names); rest != 0 {
+		result = append(result, fmt.Sprintf("MoveType(%d)", rest<<len(names)))
+	}
+	return strings.Join(result, "|")
+}
+
 // Move represents a chess move
 type Move struct {
 	From      Square   // starting position
@@ -53,7 +75,11 @@ func (ml *MoveList) String() string {
 }
 
 func (m Move) String() string {
-	return m.From.String() + " -> " + m.To.String()
+	str := m.From.String() + " -> " + m.To.String()
+	if m.Type.Has(Promotion) {
+		str += "=" + m.Promotion.String()
+	}
+	return str
 }
 
 // Lookup table for movements
@@ -68,22 +94,167 @@ var (
 	QueenDirections  = [8]int{-9, -8, -7, -1, 1, 7, 8, 9} // combined Bishop and Rook movements
 )
 
+// castlingMove describes the king and rook relocation for one castling option
+type castlingMove struct {
+	right    CastlingRights // privilege required to castle
+	kingFrom Square
+	kingTo   Square
+	rookFrom Square
+	rookTo   Square
+	path     Bitboard // squares the king and rook cross or land on, other than their own, that must be empty
+}
+
+// castlingRules holds the castling options of a game, which depend on the starting squares of king and rooks
+type castlingRules struct {
+	moves [2][2]castlingMove // [Color][King side, Queen side]
+	mask  [64]CastlingRights // rights kept when a move touches each square, king and rook squares drop theirs
+}
+
+// Sides for castlingRules.moves
+const (
+	kingSide  = 0
+	queenSide = 1
+)
+
+// standardCastling holds the castling options of standard chess, king on e1 and rooks on a1 and h1
+var standardCastling = func() *castlingRules {
+	r := newCastlingRules()
+	for color := White; color <= Black; color++ {
+		r.set(color, kingSide, 4, 7)
+		r.set(color, queenSide, 4, 0)
+	}
+	return r
+}()
+
+func newCastlingRules() *castlingRules {
+	r := &castlingRules{}
+	for sq := range r.mask {
+		r.mask[sq] = AllCastling
+	}
+	return r
+}
+
+// set defines a castling option from the files the king and rook start on.
+// Whatever the starting files, the king ends on the g or c file and the rook next to it on the f or d file.
+func (r *castlingRules) set(color Color, side, kingFile, rookFile int) {
+	rank := 0
+	if color == Black {
+		rank = 7
+	}
+	right, kingTo, rookTo := WhiteKingSide, 6, 5
+	if side == queenSide {
+		right, kingTo, rookTo = WhiteQueenSide, 2, 3
+	}
+	right <<= 2 * CastlingRights(color)
+
+	cm := castlingMove{
+		right:    right,
+		kingFrom: Square(rank*8 + kingFile),
+		kingTo:   Square(rank*8 + kingTo),
+		rookFrom: Square(rank*8 + rookFile),
+		rookTo:   Square(rank*8 + rookTo),
+	}
+	lo := min(kingFile, kingTo, rookFile, rookTo)
+	hi := max(kingFile, kingTo, rookFile, rookTo)
+	for file := lo; file <= hi; file++ {
+		cm.path = cm.path.Set(Square(rank*8 + file))
+	}
+	cm.path = cm.path.Clear(cm.kingFrom).Clear(cm.rookFrom)
+
+	// Clear the squares of a previous definition of the option before marking the new ones
+	old := r.moves[color][side]
+	if old.right != 0 {
+		r.mask[old.kingFrom] |= old.right
+		r.mask[old.rookFrom] |= old.right
+	}
+	r.moves[color][side] = cm
+	r.mask[cm.kingFrom] &^= right
+	r.mask[cm.rookFrom] &^= right
+}
+
+// castlingRules returns the castling options of the position, standard chess unless set up otherwise
+func (b *Board) castlingRules() *castlingRules {
+	if b.castling == nil {
+		return standardCastling
+	}
+	return b.castling
+}
+
+// castlingTarget returns the target square of the castling move: the king destination,
+// or the rook square in Chess960 where castling is written as the king taking its own rook.
+func (b *Board) castlingTarget(cm castlingMove) Square {
+	if b.Chess960 {
+		return cm.rookFrom
+	}
+	return cm.kingTo
+}
+
+// findCastlingMove returns the castling option matching the king movement, if any
+func (b *Board) findCastlingMove(color Color, from, to Square) (castlingMove, bool) {
+	if color > Black {
+		return castlingMove{}, false
+	}
+	for _, cm := range b.castlingRules().moves[color] {
+		if cm.kingFrom == from && b.castlingTarget(cm) == to {
+			return cm, true
+		}
+	}
+	return castlingMove{}, false
+}
+
 func isOutOfBoard(sq int) bool {
 	return sq < 0 || sq > 63
 }
 
+// GeneratePseudoLegalMoves fills the MoveList with every move available to the side to move,
+// including moves that would leave its own king in check.
+func (b *Board) GeneratePseudoLegalMoves(ml *MoveList) {
+	ml.Count = 0
+	color := b.SideToMove
+	for piece := Pawn; piece <= King; piece++ {
+		pieces := b.Pieces[color][piece]
+		for pieces != 0 {
+			sq := pieces.PopLSB()
+			b.generatePieceMoves(sq, piece, color, ml)
+		}
+	}
+}
+
+// GenerateLegalMoves fills the MoveList with the legal moves of the side to move.
+func (b *Board) GenerateLegalMoves(ml *MoveList) {
+	b.GeneratePseudoLegalMoves(ml)
+	legal := 0
+	for i := 0; i < ml.Count; i++ {
+		if b.isLegal(ml.Moves[i]) {
+			ml.Moves[legal] = ml.Moves[i]
+			legal++
+		}
+	}
+	ml.Count = legal
+}
+
+// isLegal checks that playing the pseudo-legal move does not leave the own king attacked.
+// The move is made and taken back on the board itself, leaving it as it was.
+func (b *Board) isLegal(m Move) bool {
+	color := b.SideToMove
+	undo, err := b.MakeMove(m)
+	if err != nil {
+		return false
+	}
+	kingSq, ok := b.kingSquare(color)
+	legal := !ok || !b.IsSquareAttacked(kingSq, color^1)
+	b.UnmakeMove(m, undo)
+	return legal
+}
+
 func (b *Board) generatePieceMoves(sq Square, piece Piece, color Color, ml *MoveList) {
 	switch piece {
 	case Pawn:
 		b.generatePawnMoves(sq, color, ml)
 	case Knight:
 		b.generateKnightMoves(sq, color, ml)
-	case Bishop:
-		b.generateSlidingPieceMoves(sq, color, BishopDirections[:], ml)
-	case Rook:
-		b.generateSlidingPieceMoves(sq, color, RookDirections[:], ml)
-	case Queen:
-		b.generateSlidingPieceMoves(sq, color, QueenDirections[:], ml)
+	case Bishop, Rook, Queen:
+		b.generateSlidingPieceMoves(sq, color, piece, ml)
 	case King:
 		b.generateKingMoves(sq, color, ml)
 	}
@@ -93,7 +264,6 @@ func (b *Board) generatePawnMoves(sq Square, color Color, ml *MoveList) {
 	occOppColor := b.OccupiedByColor[color^1]
 	occupied := b.OccupiedSquares
 
-	fromFile := sq.FileOf()
 	fromRank := sq.RankOf()
 
 	var forward, startRank, prePromRank int // Determine direction and starting rank based on color
@@ -144,157 +314,136 @@ func (b *Board) generatePawnMoves(sq Square, color Color, ml *MoveList) {
 	}
 
 	// Captures
-	captureDir := []int{forward - 1, forward + 1} // front diagonals
-	for _, dir := range captureDir {
-		capSq := int(sq) + dir
-		if isOutOfBoard(capSq) {
-			continue
-		}
-
-		tgtSq = Square(capSq)
-		tgtFile := tgtSq.FileOf()
-		// check for edge wrapping
-		if utils.Abs(fromFile-tgtFile) > 1 {
-			continue
+	targets := PawnAttacks(sq, color)
+	// En passant captures, only available to the side to move right after a double push
+	if b.EnPassant != NoEnPassant && color == b.SideToMove && targets.IsSet(b.EnPassant) {
+		if b.isEnPassantLegal(sq, b.EnPassant, color) {
+			ml.addMove(Move{
+				From: sq,
+				To:   b.EnPassant,
+				Type: Capture | EnPassant,
+			})
 		}
-		// Normal Captures
-		if occOppColor.IsSet(tgtSq) {
-			if fromRank == prePromRank {
-				for piece := Knight; piece <= Queen; piece++ {
-					ml.addMove(Move{
-						From:      sq,
-						To:        tgtSq,
-						Type:      Capture | Promotion,
-						Promotion: piece,
-					})
-				}
-			} else {
+	}
+	// Normal Captures
+	targets &= occOppColor
+	for targets != 0 {
+		tgtSq = targets.PopLSB()
+		if fromRank == prePromRank {
+			for piece := Knight; piece <= Queen; piece++ {
 				ml.addMove(Move{
-					From: sq,
-					To:   tgtSq,
-					Type: Capture,
+					From:      sq,
+					To:        tgtSq,
+					Type:      Capture | Promotion,
+					Promotion: piece,
 				})
 			}
-		}
-	}
-	// TODO: Add en passant captures
-	// This will require:
-	// 1. Tracking the last moved pawn
-	// 2. Checking if it moved two squares
-	// 3. Checking if our pawn is on the correct rank (5th for white, 4th for black)
-}
-
-func (b *Board) generateKingMoves(sq Square, color Color, ml *MoveList) {
-	occSameColor := b.OccupiedByColor[color]  // squares occupied by same color
-	occOppColor := b.OccupiedByColor[color^1] // squares ocuppied by opposite colors
-
-	fromFile := sq.FileOf()
-
-	for _, offset := range KingMoves {
-		toSq := int(sq) + offset
-		// Skip positions out of the board
-		if isOutOfBoard(toSq) {
-			continue
-		}
-
-		tgtSq := Square(toSq)
-		toFile := tgtSq.FileOf()
-
-		// Check if this move would wrap around the edges
-		if utils.Abs(fromFile-toFile) > 1 {
-			continue
-		}
-
-		if !occSameColor.IsSet(tgtSq) {
-			mvType := Normal
-			// Check if this is a capture
-			if occOppColor.IsSet(tgtSq) {
-				mvType = Capture
-			}
+		} else {
 			ml.addMove(Move{
 				From: sq,
 				To:   tgtSq,
-				Type: mvType,
+				Type: Capture,
 			})
 		}
 	}
+}
 
-	// TODO: Handle castling
-	// it will need to track board state, if the king/rook has moved, if the path is clear
-	// if king is not in check and if the squares related are not under attack
+// enPassantVictim returns the square of the pawn captured by an en passant move landing on tgt
+func enPassantVictim(tgt Square, color Color) Square {
+	if color == White {
+		return tgt - 8
+	}
+	return tgt + 8
 }
 
-func (b *Board) generateKnightMoves(sq Square, color Color, ml *MoveList) {
-	occSameColor := b.OccupiedByColor[color]  // squares occupied by same color
-	occOppColor := b.OccupiedByColor[color^1] // squares ocuppied by opposite colors
+// isEnPassantLegal checks that the en passant capture does not expose the own king.
+// Both pawns leave the same rank at once, so a rook or queen behind them can give a discovered check.
+func (b *Board) isEnPassantLegal(from, to Square, color Color) bool {
+	kingSq, ok := b.kingSquare(color)
+	if !ok {
+		return true
+	}
+	// Only sliders can be uncovered, look along the lines from the king with both pawns moved
+	victim := enPassantVictim(to, color)
+	occupied := b.OccupiedSquares.Clear(from).Clear(victim).Set(to)
+	enemy := &b.Pieces[color^1]
+	if RookAttacks(kingSq, occupied)&(enemy[Rook]|enemy[Queen]) != 0 {
+		return false
+	}
+	return BishopAttacks(kingSq, occupied)&(enemy[Bishop]|enemy[Queen]) == 0
+}
 
-	fromFile := sq.FileOf()
+func (b *Board) generateKingMoves(sq Square, color Color, ml *MoveList) {
+	b.addTargetMoves(sq, color, KingAttacks(sq), ml)
+	b.generateCastlingMoves(sq, color, ml)
+}
 
-	for _, offset := range KnightMoves {
-		toSq := int(sq) + offset
-		// Skip out of bounds positions
-		if isOutOfBoard(toSq) {
+func (b *Board) generateCastlingMoves(sq Square, color Color, ml *MoveList) {
+	for _, cm := range b.castlingRules().moves[color] {
+		// King and rook must be unmoved, which the castling rights track
+		if b.CastlingRights&cm.right == 0 || sq != cm.kingFrom || !b.Pieces[color][Rook].IsSet(cm.rookFrom) {
 			continue
 		}
-
-		tgtSq := Square(toSq)
-		toFile := tgtSq.FileOf()
-		// Check if this move would wrap around board edges
-		if utils.Abs(fromFile-toFile) > 2 {
+		// All squares the king and rook go through must be empty
+		if b.OccupiedSquares&cm.path != 0 {
 			continue
 		}
-		// Skip cases where the square is occupied by the same color
-		if !occSameColor.IsSet(tgtSq) {
-			mvType := Normal
-			// In case it is occupied by another color, mark it as a capture
-			if occOppColor.IsSet(tgtSq) {
-				mvType = Capture
-			}
+		// King cannot castle out of, through or into check. A rook that only shielded the king
+		// from a rook or queen on the back rank is caught by the legality check after the move.
+		if b.isKingPathAttacked(cm, color^1) {
+			continue
+		}
+		ml.addMove(Move{
+			From: sq,
+			To:   b.castlingTarget(cm),
+			Type: Castle,
+		})
+	}
+}
 
-			ml.addMove(Move{
-				From: sq,
-				To:   tgtSq,
-				Type: mvType,
-			})
+// isKingPathAttacked checks if any square the king leaves, crosses or lands on while castling is attacked
+func (b *Board) isKingPathAttacked(cm castlingMove, byColor Color) bool {
+	lo, hi := cm.kingFrom, cm.kingTo
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	for sq := lo; sq <= hi; sq++ {
+		if b.IsSquareAttacked(sq, byColor) {
+			return true
 		}
 	}
+	return false
 }
 
-func (b *Board) generateSlidingPieceMoves(sq Square, color Color, directions []int, ml *MoveList) {
-	occSameColor := b.OccupiedByColor[color]  // squares occupied by same color
-	occOppColor := b.OccupiedByColor[color^1] // squares ocuppied by opposite colors
-	fromFile := sq.FileOf()
-	for _, dir := range directions {
-		step := 1
-		lastFile := fromFile
-		for {
-			toSq := int(sq) + (dir * step)
-			// Out of bounds check
-			if isOutOfBoard(toSq) {
-				break
-			}
+func (b *Board) generateKnightMoves(sq Square, color Color, ml *MoveList) {
+	b.addTargetMoves(sq, color, KnightAttacks(sq), ml)
+}
 
-			tgtSq := Square(toSq)
-			toFile := tgtSq.FileOf()
-			// Check if this move would wrap around board edges
-			if utils.Abs(toFile-lastFile) > 1 {
-				break
-			}
-			// Stop if we reach our own piece
-			if occSameColor.IsSet(tgtSq) {
-				break
-			}
+func (b *Board) generateSlidingPieceMoves(sq Square, color Color, piece Piece, ml *MoveList) {
+	var targets Bitboard
+	switch piece {
+	case Bishop:
+		targets = BishopAttacks(sq, b.OccupiedSquares)
+	case Rook:
+		targets = RookAttacks(sq, b.OccupiedSquares)
+	case Queen:
+		targets = QueenAttacks(sq, b.OccupiedSquares)
+	}
+	b.addTargetMoves(sq, color, targets, ml)
+}
 
-			mvType := Normal
-			if occOppColor.IsSet(tgtSq) {
-				mvType = Capture
-				ml.addMove(Move{From: sq, To: tgtSq, Type: mvType})
-				break
-			}
-			ml.addMove(Move{From: sq, To: tgtSq, Type: mvType})
-			// Update current position
-			lastFile = toFile
-			step++
+// addTargetMoves adds a move from sq to every square of the attack set not occupied by the same color,
+// flagging the ones landing on opponent pieces as captures.
+func (b *Board) addTargetMoves(sq Square, color Color, targets Bitboard, ml *MoveList) {
+	occOppColor := b.OccupiedByColor[color^1] // squares ocuppied by opposite colors
+	targets &^= b.OccupiedByColor[color]      // skip squares occupied by same color
+	for targets != 0 {
+		tgtSq := targets.PopLSB()
+
+		mvType := Normal
+		if occOppColor.IsSet(tgtSq) {
+			mvType = Capture
 		}
+		ml.addMove(Move{From: sq, To: tgtSq, Type: mvType})
 	}
 }
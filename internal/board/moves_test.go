@@ -52,6 +52,47 @@ func TestIsOutOfBoard(t *testing.T) {
 	}
 }
 
+func TestMoveTypeString(t *testing.T) {
+	tests := []struct {
+		mt       MoveType
+		expected string
+	}{
+		{mt: Normal, expected: "Normal"},
+		{mt: Capture, expected: "Capture"},
+		{mt: Capture | EnPassant, expected: "Capture|EnPassant"},
+		{mt: Castle, expected: "Castle"},
+		{mt: Capture | Promotion, expected: "Capture|Promotion"},
+		{mt: Promotion * 2, expected: "MoveType(16)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result := tt.mt.String()
+			if result != tt.expected {
+				t.Errorf("Expected %s, but got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMoveString(t *testing.T) {
+	tests := []struct {
+		mv       Move
+		expected string
+	}{
+		{mv: Move{From: 12, To: 28, Type: Normal}, expected: "e2 -> e4"},
+		{mv: Move{From: 52, To: 60, Type: Promotion, Promotion: Queen}, expected: "e7 -> e8=Q"},
+		{mv: Move{From: 52, To: 61, Type: Capture | Promotion, Promotion: Knight}, expected: "e7 -> f8=N"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result := tt.mv.String()
+			if result != tt.expected {
+				t.Errorf("Expected %s, but got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestGenerateKnightMoves(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -257,17 +298,7 @@ func TestGenerateSlidingMoves(t *testing.T) {
 			tt.setup(b)
 
 			var ml MoveList
-			var directions []int
-			switch tt.piece {
-			case Bishop:
-				directions = BishopDirections[:]
-			case Rook:
-				directions = RookDirections[:]
-			case Queen:
-				directions = QueenDirections[:]
-			}
-
-			b.generateSlidingPieceMoves(tt.startSq, White, directions, &ml)
+			b.generateSlidingPieceMoves(tt.startSq, White, tt.piece, &ml)
 
 			if ml.Count != len(tt.expectedMoves) {
 				t.Errorf("Expected moveset to have %d entries, got %d instead", len(tt.expectedMoves), ml.Count)
@@ -620,7 +651,7 @@ func TestGeneratePawnMoves(t *testing.T) {
 				if move.Type != expMove.Type {
 					t.Errorf("Expected move to %s type to be %d, got %d instead", expMove.To, expMove.Type, move.Type)
 				}
-				if move.Type == Promotion && move.Promotion != expMove.Promotion {
+				if move.Type.Has(Promotion) && move.Promotion != expMove.Promotion {
 					t.Errorf("Expected promotion target to be %d, got %d instead", expMove.Promotion, move.Promotion)
 				}
 			}
@@ -628,3 +659,311 @@ func TestGeneratePawnMoves(t *testing.T) {
 
 	}
 }
+
+func TestGenerateCastlingMoves(t *testing.T) {
+	tests := []struct {
+		name          string
+		fen           string
+		startSq       Square
+		cl            Color
+		expectedMoves []Move
+	}{
+		{
+			name:    "white can castle on both sides with clear paths",
+			fen:     "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			startSq: Square(4),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 4, To: 6, Type: Castle}, // O-O
+				{From: 4, To: 2, Type: Castle}, // O-O-O
+			},
+		},
+		{
+			name:    "black can castle on both sides with clear paths",
+			fen:     "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			startSq: Square(60),
+			cl:      Black,
+			expectedMoves: []Move{
+				{From: 60, To: 62, Type: Castle}, // O-O
+				{From: 60, To: 58, Type: Castle}, // O-O-O
+			},
+		},
+		{
+			name:    "white without castling rights cannot castle",
+			fen:     "r3k2r/8/8/8/8/8/8/R3K2R w kq - 0 1",
+			startSq: Square(4),
+			cl:      White,
+		},
+		{
+			name:    "white with only queen side rights castles long",
+			fen:     "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1",
+			startSq: Square(4),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 4, To: 2, Type: Castle},
+			},
+		},
+		{
+			name:    "pieces between king and rook block castling",
+			fen:     "r3k2r/8/8/8/8/8/8/RN2K1NR w KQkq - 0 1",
+			startSq: Square(4),
+			cl:      White,
+		},
+		{
+			name:    "king in check cannot castle",
+			fen:     "r3k2r/8/8/8/8/8/4r3/R3K2R w KQkq - 0 1",
+			startSq: Square(4),
+			cl:      White,
+		},
+		{
+			name:    "king cannot castle through an attacked square",
+			fen:     "r3k2r/8/8/8/8/8/5r2/R3K2R w KQkq - 0 1",
+			startSq: Square(4),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 4, To: 2, Type: Castle},
+			},
+		},
+		{
+			name:    "king cannot castle into an attacked square",
+			fen:     "r3k2r/8/8/8/8/8/2r5/R3K2R w KQkq - 0 1",
+			startSq: Square(4),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 4, To: 6, Type: Castle},
+			},
+		},
+		{
+			name:    "attacked b1 square does not prevent castling queen side",
+			fen:     "r3k2r/8/8/8/8/8/1r6/R3K2R w KQkq - 0 1",
+			startSq: Square(4),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 4, To: 6, Type: Castle},
+				{From: 4, To: 2, Type: Castle},
+			},
+		},
+		{
+			name:    "missing rook prevents castling even with rights",
+			fen:     "r3k3/8/8/8/8/8/8/4K2R b KQkq - 0 1",
+			startSq: Square(60),
+			cl:      Black,
+			expectedMoves: []Move{
+				{From: 60, To: 58, Type: Castle},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+
+			var ml MoveList
+			b.generateCastlingMoves(tt.startSq, tt.cl, &ml)
+			if ml.Count != len(tt.expectedMoves) {
+				t.Errorf("Expected moveset to have %d entries, got %d instead", len(tt.expectedMoves), ml.Count)
+				fmt.Printf("%s\n", &ml)
+			}
+
+			genMoves := extractMoves(&ml)
+			for _, expMove := range tt.expectedMoves {
+				move, exists := genMoves[expMove.To]
+				if !exists {
+					t.Errorf("Expected move to %s not found", expMove.To)
+					continue
+				}
+				if move.From != expMove.From {
+					t.Errorf("Mismatch between expected move start at %s, got %s instead", expMove.From, move.From)
+				}
+				if move.Type != expMove.Type {
+					t.Errorf("Expected move to %s type to be %d, got %d instead", expMove.To, expMove.Type, move.Type)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateEnPassantMoves(t *testing.T) {
+	tests := []struct {
+		name          string
+		fen           string
+		startSq       Square
+		cl            Color
+		expectedMoves []Move
+	}{
+		{
+			name:    "white pawn on e5 can capture d5 pawn en passant",
+			fen:     "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2",
+			startSq: Square(36),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 36, To: 44, Type: Normal},              // e6
+				{From: 36, To: 43, Type: Capture | EnPassant}, // exd6 e.p.
+			},
+		},
+		{
+			name:    "black pawn on d4 can capture e4 pawn en passant",
+			fen:     "4k3/8/8/8/3pP3/8/8/4K3 b - e3 0 1",
+			startSq: Square(27),
+			cl:      Black,
+			expectedMoves: []Move{
+				{From: 27, To: 19, Type: Normal},              // d3
+				{From: 27, To: 20, Type: Capture | EnPassant}, // dxe3 e.p.
+			},
+		},
+		{
+			name:    "pawn not adjacent to the target cannot capture en passant",
+			fen:     "4k3/8/8/3p2P1/8/8/8/4K3 w - d6 0 2",
+			startSq: Square(38),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 38, To: 46, Type: Normal}, // g6
+			},
+		},
+		{
+			name:    "en passant on a file does not wrap to h file",
+			fen:     "4k3/8/8/Pp5p/8/8/8/4K3 w - b6 0 2",
+			startSq: Square(32),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 32, To: 40, Type: Normal},              // a6
+				{From: 32, To: 41, Type: Capture | EnPassant}, // axb6 e.p.
+			},
+		},
+		{
+			name:    "en passant exposing the king along the rank is illegal",
+			fen:     "8/8/8/K2pP2r/8/8/8/4k3 w - d6 0 2",
+			startSq: Square(36),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 36, To: 44, Type: Normal}, // e6
+			},
+		},
+		{
+			name:    "en passant removing the pawn that blocks a diagonal is illegal",
+			fen:     "4k3/1b6/8/3pP3/8/8/8/7K w - d6 0 2",
+			startSq: Square(36),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 36, To: 44, Type: Normal}, // e6
+			},
+		},
+		{
+			name:    "en passant with a blocker between king and rook stays legal",
+			fen:     "8/8/8/KN1pP2r/8/8/8/4k3 w - d6 0 2",
+			startSq: Square(36),
+			cl:      White,
+			expectedMoves: []Move{
+				{From: 36, To: 44, Type: Normal},              // e6
+				{From: 36, To: 43, Type: Capture | EnPassant}, // exd6 e.p.
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+
+			var ml MoveList
+			b.generatePawnMoves(tt.startSq, tt.cl, &ml)
+			if ml.Count != len(tt.expectedMoves) {
+				t.Errorf("Expected moveset to have %d entries, got %d instead", len(tt.expectedMoves), ml.Count)
+				fmt.Printf("%s\n", &ml)
+			}
+
+			genMoves := extractMoves(&ml)
+			for _, expMove := range tt.expectedMoves {
+				move, exists := genMoves[expMove.To]
+				if !exists {
+					t.Errorf("Expected move to %s not found", expMove.To)
+					continue
+				}
+				if move.From != expMove.From {
+					t.Errorf("Mismatch between expected move start at %s, got %s instead", expMove.From, move.From)
+				}
+				if move.Type != expMove.Type {
+					t.Errorf("Expected move to %s type to be %d, got %d instead", expMove.To, expMove.Type, move.Type)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateLegalMoves(t *testing.T) {
+	tests := []struct {
+		name            string
+		fen             string
+		wantPseudoLegal int
+		wantLegal       int
+	}{
+		{
+			name:            "initial position",
+			fen:             "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			wantPseudoLegal: 20,
+			wantLegal:       20,
+		},
+		{
+			name:            "kiwipete",
+			fen:             "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			wantPseudoLegal: 48,
+			wantLegal:       48,
+		},
+		{
+			name:            "pinned bishop cannot leave the file",
+			fen:             "4k3/4r3/8/8/8/8/4B3/4K3 w - - 0 1",
+			wantPseudoLegal: 13,
+			wantLegal:       4,
+		},
+		{
+			name:            "king in check must be resolved",
+			fen:             "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+			wantPseudoLegal: 38,
+			wantLegal:       6,
+		},
+		{
+			name:            "checkmated side has no legal moves",
+			fen:             "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3",
+			wantPseudoLegal: 19,
+			wantLegal:       0,
+		},
+		{
+			name:            "stalemated side has no legal moves",
+			fen:             "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+			wantPseudoLegal: 3,
+			wantLegal:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+
+			var ml MoveList
+			b.GeneratePseudoLegalMoves(&ml)
+			if ml.Count != tt.wantPseudoLegal {
+				t.Errorf("Expected %d pseudo-legal moves, got %d instead", tt.wantPseudoLegal, ml.Count)
+			}
+
+			b.GenerateLegalMoves(&ml)
+			if ml.Count != tt.wantLegal {
+				t.Errorf("Expected %d legal moves, got %d instead", tt.wantLegal, ml.Count)
+				fmt.Printf("%s\n", &ml)
+			}
+			for i := 0; i < ml.Count; i++ {
+				after := *b
+				if err := after.PlayMove(ml.Moves[i]); err != nil {
+					t.Errorf("legal move %v could not be played: %v", ml.Moves[i], err)
+				}
+				if kingSq, ok := after.kingSquare(b.SideToMove); ok && after.IsSquareAttacked(kingSq, after.SideToMove) {
+					t.Errorf("legal move %v leaves the king in check", ml.Moves[i])
+				}
+			}
+		})
+	}
+}
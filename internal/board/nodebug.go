@@ -0,0 +1,6 @@
+//go:build !debug
+
+package board
+
+// debugChecks validates the board after every MakeMove and UnmakeMove, enable it with -tags debug.
+const debugChecks = false
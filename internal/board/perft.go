@@ -0,0 +1,50 @@
+package board
+
+// Perft counts the leaf nodes of the legal move tree up to the given depth.
+// Comparing the counts against known values is the standard check for move generation bugs.
+func (b *Board) Perft(depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+
+	var ml MoveList
+	b.GenerateLegalMoves(&ml)
+	// Leaf parents only need the amount of legal moves
+	if depth == 1 {
+		return uint64(ml.Count)
+	}
+
+	var nodes uint64
+	for i := 0; i < ml.Count; i++ {
+		m := ml.Moves[i]
+		undo, err := b.MakeMove(m)
+		if err != nil {
+			continue
+		}
+		nodes += b.Perft(depth - 1)
+		b.UnmakeMove(m, undo)
+	}
+	return nodes
+}
+
+// PerftDivide returns the Perft leaf count below each legal move of the current position,
+// so a wrong total can be narrowed down to the root move where it diverges.
+func (b *Board) PerftDivide(depth int) map[Move]uint64 {
+	divide := make(map[Move]uint64)
+	if depth <= 0 {
+		return divide
+	}
+
+	var ml MoveList
+	b.GenerateLegalMoves(&ml)
+	for i := 0; i < ml.Count; i++ {
+		m := ml.Moves[i]
+		undo, err := b.MakeMove(m)
+		if err != nil {
+			continue
+		}
+		divide[m] = b.Perft(depth - 1)
+		b.UnmakeMove(m, undo)
+	}
+	return divide
+}
@@ -0,0 +1,127 @@
+package board
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Reference positions and node counts from https://www.chessprogramming.org/Perft_Results
+var perftPositions = []struct {
+	name  string
+	fen   string
+	nodes []uint64 // expected leaf nodes, index 0 is depth 1
+}{
+	{
+		name:  "initial position",
+		fen:   "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		nodes: []uint64{20, 400, 8902, 197281},
+	},
+	{
+		name:  "kiwipete",
+		fen:   "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		nodes: []uint64{48, 2039, 97862},
+	},
+	{
+		name:  "position 3",
+		fen:   "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		nodes: []uint64{14, 191, 2812, 43238},
+	},
+	{
+		name:  "position 4",
+		fen:   "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		nodes: []uint64{6, 264, 9467},
+	},
+	{
+		name:  "position 4 mirrored",
+		fen:   "r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1",
+		nodes: []uint64{6, 264, 9467},
+	},
+	{
+		name:  "position 5",
+		fen:   "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		nodes: []uint64{44, 1486, 62379},
+	},
+	{
+		name:  "position 6",
+		fen:   "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		nodes: []uint64{46, 2079, 89890},
+	},
+	// Chess960 positions from https://www.chessprogramming.org/Chess960_Perft_Results
+	{
+		name:  "chess960 position 1",
+		fen:   "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+		nodes: []uint64{21, 528, 12189, 326672},
+	},
+	{
+		name:  "chess960 position 2",
+		fen:   "2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9",
+		nodes: []uint64{21, 807, 18002},
+	},
+	{
+		name:  "chess960 position 3",
+		fen:   "b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9",
+		nodes: []uint64{20, 479, 10471, 273318},
+	},
+	{
+		name:  "chess960 position 4",
+		fen:   "qbbnnrkr/2pp2pp/p7/1p2pp2/8/P3PP2/1PPP1KPP/QBBNNR1R w hf - 0 9",
+		nodes: []uint64{22, 593, 13440},
+	},
+}
+
+func TestPerft(t *testing.T) {
+	for _, tt := range perftPositions {
+		for i, want := range tt.nodes {
+			depth := i + 1
+			// Deeper searches take a while, keep them out of short runs
+			if testing.Short() && want > 10000 {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s depth %d", tt.name, depth), func(t *testing.T) {
+				b, err := ParseFEN(tt.fen)
+				if err != nil {
+					t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+				}
+				before := *b
+
+				result := b.Perft(depth)
+				if result != want {
+					t.Errorf("Perft(%d) = %d; want %d", depth, result, want)
+				}
+				if *b != before {
+					t.Errorf("Perft(%d) did not restore the board, got %s", depth, b.ToFEN())
+				}
+			})
+		}
+	}
+}
+
+func TestPerftDivide(t *testing.T) {
+	for _, tt := range perftPositions {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+
+			divide := b.PerftDivide(2)
+			if uint64(len(divide)) != tt.nodes[0] {
+				t.Errorf("expected %d root moves, got %d instead", tt.nodes[0], len(divide))
+			}
+			var total uint64
+			for _, nodes := range divide {
+				total += nodes
+			}
+			if total != tt.nodes[1] {
+				t.Errorf("expected divide to sum up to %d, got %d instead", tt.nodes[1], total)
+			}
+		})
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	board, _ := ParseFEN(perftPositions[1].fen)
+	for i := 0; i < b.N; i++ {
+		board.Perft(3)
+	}
+}
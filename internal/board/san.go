@@ -0,0 +1,147 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MoveToSAN writes a legal move in Standard Algebraic Notation, like "Nbd7", "exd6", "e8=Q+" or "O-O-O#".
+func (b *Board) MoveToSAN(m Move) string {
+	var sb strings.Builder
+	_, piece := b.PieceAt(m.From)
+
+	switch {
+	case m.Type.Has(Castle):
+		if m.To.FileOf() > m.From.FileOf() {
+			sb.WriteString("O-O")
+		} else {
+			sb.WriteString("O-O-O")
+		}
+	case piece == Pawn:
+		// Pawn captures name the file the pawn comes from
+		if m.Type.Has(Capture) {
+			sb.WriteRune(filesLbl[m.From.FileOf()])
+			sb.WriteByte('x')
+		}
+		sb.WriteString(m.To.String())
+		if m.Type.Has(Promotion) {
+			sb.WriteByte('=')
+			sb.WriteString(m.Promotion.String())
+		}
+	default:
+		sb.WriteString(piece.String())
+		sb.WriteString(b.disambiguation(m, piece))
+		if m.Type.Has(Capture) {
+			sb.WriteByte('x')
+		}
+		sb.WriteString(m.To.String())
+	}
+
+	// Check and checkmate suffixes
+	after := *b
+	if after.PlayMove(m) == nil && after.InCheck() {
+		var ml MoveList
+		after.GenerateLegalMoves(&ml)
+		if ml.Count == 0 {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('+')
+		}
+	}
+	return sb.String()
+}
+
+// disambiguation returns the source file, rank or square needed to tell the move apart
+// from moves of other pieces of the same type to the same square.
+func (b *Board) disambiguation(m Move, piece Piece) string {
+	var ml MoveList
+	b.GenerateLegalMoves(&ml)
+	ambiguous, sameFile, sameRank := false, false, false
+	for i := 0; i < ml.Count; i++ {
+		other := ml.Moves[i]
+		if other.To != m.To || other.From == m.From {
+			continue
+		}
+		if _, p := b.PieceAt(other.From); p != piece {
+			continue
+		}
+		ambiguous = true
+		sameFile = sameFile || other.From.FileOf() == m.From.FileOf()
+		sameRank = sameRank || other.From.RankOf() == m.From.RankOf()
+	}
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return string(filesLbl[m.From.FileOf()])
+	case !sameRank:
+		return string(ranksLbl[m.From.RankOf()])
+	}
+	return m.From.String()
+}
+
+// ParseSAN finds the legal move written in Standard Algebraic Notation.
+// It accepts common variations: castling with zeros, missing or extra check marks,
+// annotations like "!?" and promotions without "=" such as "e8Q".
+func (b *Board) ParseSAN(san string) (Move, error) {
+	s := strings.TrimRight(san, "+#!?")
+	var ml MoveList
+	b.GenerateLegalMoves(&ml)
+
+	switch s {
+	case "O-O", "0-0", "O-O-O", "0-0-0":
+		kingSide := len(s) == 3
+		for i := 0; i < ml.Count; i++ {
+			m := ml.Moves[i]
+			if m.Type.Has(Castle) && (m.To.FileOf() > m.From.FileOf()) == kingSide {
+				return m, nil
+			}
+		}
+		return Move{}, fmt.Errorf("illegal castling %s in %s", san, b.ToFEN())
+	}
+
+	piece := Pawn
+	if len(s) > 0 && strings.IndexByte("NBRQK", s[0]) >= 0 {
+		_, piece = pieceFromRune(rune(s[0]))
+		s = s[1:]
+	}
+	promotion := Empty
+	if piece == Pawn && len(s) > 2 && strings.IndexByte("NBRQnbrq", s[len(s)-1]) >= 0 {
+		_, promotion = pieceFromRune(rune(s[len(s)-1]))
+		s = strings.TrimSuffix(s[:len(s)-1], "=")
+	}
+	if len(s) < 2 {
+		return Move{}, fmt.Errorf("malformed move %q", san)
+	}
+	to, err := ParseSquare(s[len(s)-2:])
+	if err != nil {
+		return Move{}, fmt.Errorf("malformed move %q: %v", san, err)
+	}
+	// What is left between the piece and the target square names the source file, rank or square
+	from := strings.TrimSuffix(s[:len(s)-2], "x")
+	if len(from) > 2 || strings.ContainsAny(from, "x-") {
+		return Move{}, fmt.Errorf("malformed move %q", san)
+	}
+
+	var found []Move
+	for i := 0; i < ml.Count; i++ {
+		m := ml.Moves[i]
+		if m.To != to || m.Promotion != promotion || m.Type.Has(Castle) {
+			continue
+		}
+		if _, p := b.PieceAt(m.From); p != piece {
+			continue
+		}
+		if !strings.HasPrefix(m.From.String(), from) && !strings.HasSuffix(m.From.String(), from) {
+			continue
+		}
+		found = append(found, m)
+	}
+	switch len(found) {
+	case 0:
+		return Move{}, fmt.Errorf("illegal move %s in %s", san, b.ToFEN())
+	case 1:
+		return found[0], nil
+	}
+	return Move{}, fmt.Errorf("ambiguous move %s in %s", san, b.ToFEN())
+}
@@ -0,0 +1,106 @@
+package board
+
+import "testing"
+
+func TestMoveToSAN(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move Move
+		want string
+	}{
+		{"pawn push", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", Move{From: 12, To: 28, Type: Normal}, "e4"},
+		{"knight move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", Move{From: 6, To: 21, Type: Normal}, "Nf3"},
+		{"pawn capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", Move{From: 28, To: 35, Type: Capture}, "exd5"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2", Move{From: 36, To: 43, Type: Capture | EnPassant}, "exd6"},
+		{"piece capture", "4k3/8/8/3p4/8/4N3/8/4K3 w - - 0 1", Move{From: 20, To: 35, Type: Capture}, "Nxd5"},
+		{"file disambiguation", "4k3/8/8/8/8/8/4K3/R6R w - - 0 1", Move{From: 7, To: 3, Type: Normal}, "Rhd1"},
+		{"rank disambiguation", "4k3/R7/8/8/8/8/R7/4K3 w - - 0 1", Move{From: 8, To: 32, Type: Normal}, "R2a5"},
+		{"square disambiguation", "k7/8/8/8/8/2Q1Q3/8/2Q1Q1K1 w - - 0 1", Move{From: 2, To: 11, Type: Normal}, "Qc1d2"},
+		{"pinned piece needs no disambiguation", "4k3/8/8/8/1b6/8/3N1N2/4K3 w - - 0 1", Move{From: 13, To: 28, Type: Normal}, "Ne4"},
+		{"promotion with check", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", Move{From: 52, To: 60, Type: Promotion, Promotion: Queen}, "e8=Q"},
+		{"capture promotion", "3r4/4P3/8/8/8/8/k7/4K3 w - - 0 1", Move{From: 52, To: 59, Type: Capture | Promotion, Promotion: Knight}, "exd8=N"},
+		{"kingside castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", Move{From: 4, To: 6, Type: Castle}, "O-O"},
+		{"queenside castling with check", "3k4/8/8/8/8/8/8/R3K3 w Q - 0 1", Move{From: 4, To: 2, Type: Castle}, "O-O-O+"},
+		{"check", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", Move{From: 0, To: 56, Type: Normal}, "Ra8+"},
+		{"checkmate", "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq - 0 2", Move{From: 59, To: 31, Type: Normal}, "Qh4#"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			if got := b.MoveToSAN(tt.move); got != tt.want {
+				t.Errorf("expected %s, got %s instead", tt.want, got)
+			}
+			// Writing and parsing round trips
+			m, err := b.ParseSAN(tt.want)
+			if err != nil {
+				t.Fatalf("Expected no error parsing %s, got %v instead", tt.want, err)
+			}
+			if m != tt.move {
+				t.Errorf("expected parsed move %v, got %v instead", tt.move, m)
+			}
+		})
+	}
+}
+
+func TestParseSAN(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		san  string
+		want Move
+	}{
+		{"castling with zeros", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "0-0-0", Move{From: 60, To: 58, Type: Castle}},
+		{"missing check mark", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "Ra8", Move{From: 0, To: 56, Type: Normal}},
+		{"annotated move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e4!?", Move{From: 12, To: 28, Type: Normal}},
+		{"promotion without equals", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8Q", Move{From: 52, To: 60, Type: Promotion, Promotion: Queen}},
+		{"lowercase promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8=r", Move{From: 52, To: 60, Type: Promotion, Promotion: Rook}},
+		{"unneeded disambiguation", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "Ng1f3", Move{From: 6, To: 21, Type: Normal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			m, err := b.ParseSAN(tt.san)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if m != tt.want {
+				t.Errorf("expected move %v, got %v instead", tt.want, m)
+			}
+		})
+	}
+}
+
+func TestParseSANError(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		san  string
+	}{
+		{"empty", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", ""},
+		{"no target square", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "N"},
+		{"outside the board", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "Ni3"},
+		{"illegal move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e5"},
+		{"castling without rights", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "O-O"},
+		{"ambiguous move", "4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "Rd1"},
+		{"missing promotion piece", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8"},
+		{"pinned piece", "4k3/8/8/8/1b6/8/3N4/4K3 w - - 0 1", "Ne4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			if m, err := b.ParseSAN(tt.san); err == nil {
+				t.Errorf("expected error, got %v instead", m)
+			}
+		})
+	}
+}
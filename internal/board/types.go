@@ -1,5 +1,11 @@
 package board
 
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
 // Custom Type for Piece
 type Piece uint8
 
@@ -30,6 +36,14 @@ func (sq Square) String() string {
 	return string(result)
 }
 
+// ParseSquare converts a square in algebraic notation (e.g. "e4") to a Square.
+func ParseSquare(s string) (Square, error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return 0, fmt.Errorf("invalid square %q", s)
+	}
+	return Square(int(s[1]-'1')*8 + int(s[0]-'a')), nil
+}
+
 func (sq Square) FileOf() int {
 	return int(sq % 8)
 }
@@ -38,6 +52,45 @@ func (sq Square) RankOf() int {
 	return int(sq / 8)
 }
 
+// Custom type for the castling privileges still available in a position
+type CastlingRights uint8
+
+// Flags for CastlingRights, combined with bitwise OR
+const (
+	WhiteKingSide CastlingRights = 1 << iota
+	WhiteQueenSide
+	BlackKingSide
+	BlackQueenSide
+
+	NoCastling  CastlingRights = 0
+	AllCastling                = WhiteKingSide | WhiteQueenSide | BlackKingSide | BlackQueenSide
+)
+
+// String returns the castling rights in FEN notation, "-" when none are available.
+func (cr CastlingRights) String() string {
+	if cr == NoCastling {
+		return "-"
+	}
+	var result string
+	if cr&WhiteKingSide != 0 {
+		result += "K"
+	}
+	if cr&WhiteQueenSide != 0 {
+		result += "Q"
+	}
+	if cr&BlackKingSide != 0 {
+		result += "k"
+	}
+	if cr&BlackQueenSide != 0 {
+		result += "q"
+	}
+	return result
+}
+
+// NoEnPassant marks the absence of an en passant target square.
+// a1 can never be an en passant target, so the zero value of a Board has none.
+const NoEnPassant Square = 0
+
 // Constants for Piece Enum
 const (
 	Empty Piece = iota
@@ -66,6 +119,73 @@ func (bb Bitboard) IsSet(sq Square) bool {
 	return (bb & (1 << sq)) != 0
 }
 
+// Count returns the number of set bits (population count) in the Bitboard.
+func (bb Bitboard) Count() int {
+	return bits.OnesCount64(uint64(bb))
+}
+
+// LSB returns the least significant set square, the Bitboard must not be empty.
+func (bb Bitboard) LSB() Square {
+	return Square(bits.TrailingZeros64(uint64(bb)))
+}
+
+// MSB returns the most significant set square, the Bitboard must not be empty.
+func (bb Bitboard) MSB() Square {
+	return Square(63 - bits.LeadingZeros64(uint64(bb)))
+}
+
+// PopLSB clears the least significant set square and returns it, the Bitboard must not be empty.
+func (bb *Bitboard) PopLSB() Square {
+	sq := bb.LSB()
+	*bb &= *bb - 1
+	return sq
+}
+
+// Squares returns an iterator over the set squares, from a1 to h8.
+func (bb Bitboard) Squares() iter.Seq[Square] {
+	return func(yield func(Square) bool) {
+		for bb != 0 {
+			if !yield(bb.PopLSB()) {
+				return
+			}
+		}
+	}
+}
+
+// Directional shifts move every set square one step, squares leaving the board are dropped
+// and the file masks keep pieces from wrapping around to the opposite edge.
+
+func (bb Bitboard) North() Bitboard     { return bb << 8 }
+func (bb Bitboard) South() Bitboard     { return bb >> 8 }
+func (bb Bitboard) East() Bitboard      { return (bb &^ FileH) << 1 }
+func (bb Bitboard) West() Bitboard      { return (bb &^ FileA) >> 1 }
+func (bb Bitboard) NorthEast() Bitboard { return (bb &^ FileH) << 9 }
+func (bb Bitboard) NorthWest() Bitboard { return (bb &^ FileA) << 7 }
+func (bb Bitboard) SouthEast() Bitboard { return (bb &^ FileH) >> 7 }
+func (bb Bitboard) SouthWest() Bitboard { return (bb &^ FileA) >> 9 }
+
+// FileFill returns every file that has at least one set square.
+func (bb Bitboard) FileFill() Bitboard {
+	var result Bitboard
+	for _, file := range fileMasks {
+		if bb&file != 0 {
+			result |= file
+		}
+	}
+	return result
+}
+
+// RankFill returns every rank that has at least one set square.
+func (bb Bitboard) RankFill() Bitboard {
+	var result Bitboard
+	for _, rank := range rankMasks {
+		if bb&rank != 0 {
+			result |= rank
+		}
+	}
+	return result
+}
+
 func (bb Bitboard) String() string {
 	var result string
 	for rank := 7; rank >= 0; rank-- {
@@ -102,16 +222,35 @@ const (
 	Rank8 Bitboard = Rank1 << (8 * 7)
 )
 
+// File and rank masks indexed by Square.FileOf and Square.RankOf
+var (
+	fileMasks = [8]Bitboard{FileA, FileB, FileC, FileD, FileE, FileF, FileG, FileH}
+	rankMasks = [8]Bitboard{Rank1, Rank2, Rank3, Rank4, Rank5, Rank6, Rank7, Rank8}
+)
+
 type Board struct {
 	Pieces [2][7]Bitboard // Bitboards for each piecetype [Color][Type]
 
 	// Combined Bitboards for faster lookup
 	OccupiedSquares Bitboard    // All pieces
 	OccupiedByColor [2]Bitboard // All pieces of the same color
+	mailbox         [64]Piece   // Piece type on each square, colors come from OccupiedByColor
 
 	// Positional information
-	SideToMove    Color
-	FullMoveCount int
+	SideToMove     Color
+	CastlingRights CastlingRights // castling privileges still available
+	EnPassant      Square         // en passant target square, NoEnPassant if none
+	HalfMoveClock  int            // half moves since the last capture or pawn move
+	FullMoveCount  int
+
+	// Chess960 writes castling as the king taking its own rook, in moves and in UCI, as Chess960
+	// needs when the king may not move. It is set for Chess960 starting positions and FEN strings.
+	Chess960 bool
+	castling *castlingRules // castling options when king and rooks do not start on the standard squares
+
+	// Zobrist keys identifying the position, updated incrementally by MakeMove
+	Hash     uint64 // pieces, side to move, castling rights and en passant file
+	PawnHash uint64 // pawns only, for pawn structure caches
 }
 
 // SetInitialBoard initializes the chess board with the starting positions of all pieces.
@@ -131,24 +270,79 @@ func (b *Board) SetInitialBoard() {
 	b.Pieces[Black][Queen] = b.Pieces[White][Queen] << 56
 	b.Pieces[Black][King] = b.Pieces[White][King] << 56
 
-	// Update all occupied squares on the boards
-	b.UpdateOccupiedSquares()
-
 	b.SideToMove = White
+	b.CastlingRights = AllCastling
+	b.Chess960 = false
+	b.castling = nil
+	b.EnPassant = NoEnPassant
+	b.HalfMoveClock = 0
 	b.FullMoveCount = 1
+
+	// Update all occupied squares and position keys on the boards
+	b.UpdateOccupiedSquares()
 }
 
-// UpdateOccupiedSquares updates the occupied squares on the board for both white and black pieces.
+// UpdateOccupiedSquares recomputes the occupied squares for both white and black pieces, the mailbox
+// and the position keys. Moves update them incrementally, so it is only needed after setting up a Board by hand.
 func (b *Board) UpdateOccupiedSquares() {
 	b.OccupiedByColor[White] = 0
 	b.OccupiedByColor[Black] = 0
+	b.mailbox = [64]Piece{}
 
 	for piece := Pawn; piece <= King; piece++ {
 		b.OccupiedByColor[White] |= b.Pieces[White][piece]
 		b.OccupiedByColor[Black] |= b.Pieces[Black][piece]
+		for sq := range (b.Pieces[White][piece] | b.Pieces[Black][piece]).Squares() {
+			b.mailbox[sq] = piece
+		}
 	}
 
 	b.OccupiedSquares = b.OccupiedByColor[White] | b.OccupiedByColor[Black]
+	b.UpdateHash()
+}
+
+// PieceAt returns the piece and its color at the given square in constant time,
+// reading the mailbox kept in sync with the bitboards.
+func (b *Board) PieceAt(sq Square) (Color, Piece) {
+	piece := b.mailbox[sq]
+	if piece == Empty {
+		return None, Empty
+	}
+	if b.OccupiedByColor[White].IsSet(sq) {
+		return White, piece
+	}
+	return Black, piece
+}
+
+// Validate checks that the redundant board representations agree with the piece bitboards:
+// no square holds two pieces, and the occupancy bitboards, mailbox and position keys match them.
+func (b *Board) Validate() error {
+	var seen [2]Bitboard
+	for color := White; color <= Black; color++ {
+		for piece := Pawn; piece <= King; piece++ {
+			bb := b.Pieces[color][piece]
+			if overlap := bb & (seen[White] | seen[Black]); overlap != 0 {
+				return fmt.Errorf("square %v holds more than one piece", overlap.LSB())
+			}
+			seen[color] |= bb
+		}
+	}
+	if seen != b.OccupiedByColor {
+		return fmt.Errorf("occupied squares by color do not match the pieces on the board")
+	}
+	if seen[White]|seen[Black] != b.OccupiedSquares {
+		return fmt.Errorf("occupied squares do not match the pieces on the board")
+	}
+	for sq := Square(0); sq < 64; sq++ {
+		wantColor, wantPiece := b.GetPieceAt(sq)
+		if color, piece := b.PieceAt(sq); color != wantColor || piece != wantPiece {
+			return fmt.Errorf("mailbox has %v %v on %v, bitboards have %v %v", color, &piece, sq, wantColor, &wantPiece)
+		}
+	}
+	if hash, pawnHash := b.computeHash(); hash != b.Hash || pawnHash != b.PawnHash {
+		return fmt.Errorf("position keys do not match the position")
+	}
+	return nil
 }
 
 // GetPieceAt returns the piece and its color at the given square
@@ -0,0 +1,61 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UCI writes the move in the long algebraic notation used by the UCI protocol:
+// source and target squares followed by the lowercase promotion piece, like "e2e4" or "e7e8q".
+// Castling is written as the king move, "e1g1", or as the king taking its own rook, "e1h1", in Chess960.
+func (m Move) UCI() string {
+	s := m.From.String() + m.To.String()
+	if m.Type.Has(Promotion) {
+		s += strings.ToLower(m.Promotion.String())
+	}
+	return s
+}
+
+// ParseUCIMove finds the legal move written in UCI long algebraic notation, filling in its type.
+// Castling is accepted both as the king move "e1g1" and as the Chess960 king takes rook form "e1h1",
+// only the latter in Chess960 where castling moves are generated in that form.
+func (b *Board) ParseUCIMove(s string) (Move, error) {
+	if len(s) != 4 && len(s) != 5 {
+		return Move{}, fmt.Errorf("malformed UCI move %q", s)
+	}
+	from, err := ParseSquare(s[0:2])
+	if err != nil {
+		return Move{}, fmt.Errorf("malformed UCI move %q: %v", s, err)
+	}
+	to, err := ParseSquare(s[2:4])
+	if err != nil {
+		return Move{}, fmt.Errorf("malformed UCI move %q: %v", s, err)
+	}
+	promotion := Empty
+	if len(s) == 5 {
+		if strings.IndexByte("nbrq", s[4]) < 0 {
+			return Move{}, fmt.Errorf("malformed UCI move %q: invalid promotion %q", s, s[4])
+		}
+		_, promotion = pieceFromRune(rune(s[4]))
+	}
+
+	// Outside Chess960 castling moves are generated as the king move, so translate the king taking its own rook
+	color, piece := b.PieceAt(from)
+	if toColor, toPiece := b.PieceAt(to); !b.Chess960 && piece == King && toPiece == Rook && toColor == color {
+		for _, cm := range b.castlingRules().moves[color] {
+			if cm.kingFrom == from && cm.rookFrom == to {
+				to = cm.kingTo
+			}
+		}
+	}
+
+	var ml MoveList
+	b.GenerateLegalMoves(&ml)
+	for i := 0; i < ml.Count; i++ {
+		m := ml.Moves[i]
+		if m.From == from && m.To == to && m.Promotion == promotion {
+			return m, nil
+		}
+	}
+	return Move{}, fmt.Errorf("illegal move %s in %s", s, b.ToFEN())
+}
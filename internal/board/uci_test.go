@@ -0,0 +1,88 @@
+package board
+
+import "testing"
+
+func TestMoveUCI(t *testing.T) {
+	tests := []struct {
+		name string
+		move Move
+		want string
+	}{
+		{"pawn push", Move{From: 12, To: 28, Type: Normal}, "e2e4"},
+		{"capture", Move{From: 28, To: 35, Type: Capture}, "e4d5"},
+		{"promotion", Move{From: 52, To: 60, Type: Promotion, Promotion: Queen}, "e7e8q"},
+		{"capture promotion", Move{From: 52, To: 59, Type: Capture | Promotion, Promotion: Knight}, "e7d8n"},
+		{"castling", Move{From: 4, To: 6, Type: Castle}, "e1g1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.move.UCI(); got != tt.want {
+				t.Errorf("expected %s, got %s instead", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseUCIMove(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		uci  string
+		want Move
+	}{
+		{"pawn push", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e4", Move{From: 12, To: 28, Type: Normal}},
+		{"capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", Move{From: 28, To: 35, Type: Capture}},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2", "e5d6", Move{From: 36, To: 43, Type: Capture | EnPassant}},
+		{"promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8q", Move{From: 52, To: 60, Type: Promotion, Promotion: Queen}},
+		{"underpromotion capture", "3r4/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7d8n", Move{From: 52, To: 59, Type: Capture | Promotion, Promotion: Knight}},
+		{"castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", Move{From: 4, To: 6, Type: Castle}},
+		{"castling as king takes rook", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8a8", Move{From: 60, To: 58, Type: Castle}},
+		{"chess960 castling", "4k3/8/8/8/8/8/8/1RK4R w HB - 0 1", "c1b1", Move{From: 2, To: 1, Type: Castle}},
+		{"chess960 king move next to the rook", "4k3/8/8/8/8/8/8/1RK4R w HB - 0 1", "c1d1", Move{From: 2, To: 3, Type: Normal}},
+		{"chess960 castling with the king in place", "4k3/8/8/8/8/8/8/R5KR w HA - 0 1", "g1h1", Move{From: 6, To: 7, Type: Castle}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			m, err := b.ParseUCIMove(tt.uci)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if m != tt.want {
+				t.Errorf("expected move %v (%v), got %v (%v) instead", tt.want, tt.want.Type, m, m.Type)
+			}
+		})
+	}
+}
+
+func TestParseUCIMoveError(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		uci  string
+	}{
+		{"too short", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e"},
+		{"outside the board", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e9"},
+		{"invalid promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8k"},
+		{"missing promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8"},
+		{"illegal move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e5"},
+		{"empty source square", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e3e4"},
+		{"castling without rights", "r3k2r/8/8/8/8/8/8/R3K2R w kq - 0 1", "e1h1"},
+		{"chess960 castling as king move", "4k3/8/8/8/8/8/8/R3K2R w HA - 0 1", "e1g1"},
+		{"chess960 castling rook shielding the king", "4k3/8/8/8/8/8/8/rRK4R w HB - 0 1", "c1b1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			if m, err := b.ParseUCIMove(tt.uci); err == nil {
+				t.Errorf("expected error, got %v instead", m)
+			}
+		})
+	}
+}
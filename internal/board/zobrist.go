@@ -0,0 +1,87 @@
+package board
+
+// Zobrist keys, one random number per position feature. The hash of a position is the XOR
+// of the keys of its features, so making a move only needs to toggle the keys that changed.
+var (
+	zobristPieces    [2][7][64]uint64 // [Color][Piece][Square]
+	zobristSide      uint64           // toggled when Black is to move
+	zobristCastling  [16]uint64       // one key per combination of castling rights
+	zobristEnPassant [8]uint64        // one key per file of the en passant target
+)
+
+func init() {
+	// Fixed seed, so hashes stay the same across runs and can be stored
+	rng := splitMix64(0x9E3779B97F4A7C15)
+	for color := White; color <= Black; color++ {
+		for piece := Pawn; piece <= King; piece++ {
+			for sq := range zobristPieces[color][piece] {
+				zobristPieces[color][piece][sq] = rng()
+			}
+		}
+	}
+	zobristSide = rng()
+	for i := range zobristCastling {
+		zobristCastling[i] = rng()
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = rng()
+	}
+}
+
+// splitMix64 returns a SplitMix64 pseudo random generator, small and with a stable output sequence.
+func splitMix64(seed uint64) func() uint64 {
+	state := seed
+	return func() uint64 {
+		state += 0x9E3779B97F4A7C15
+		z := state
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		return z ^ (z >> 31)
+	}
+}
+
+// UpdateHash recomputes the Zobrist keys of the position from scratch.
+func (b *Board) UpdateHash() {
+	b.Hash, b.PawnHash = b.computeHash()
+}
+
+// computeHash returns the full position key and the pawn structure key.
+func (b *Board) computeHash() (hash, pawnHash uint64) {
+	for color := White; color <= Black; color++ {
+		for piece := Pawn; piece <= King; piece++ {
+			for sq := range b.Pieces[color][piece].Squares() {
+				hash ^= zobristPieces[color][piece][sq]
+				if piece == Pawn {
+					pawnHash ^= zobristPieces[color][piece][sq]
+				}
+			}
+		}
+	}
+	if b.SideToMove == Black {
+		hash ^= zobristSide
+	}
+	hash ^= zobristCastling[b.CastlingRights&AllCastling]
+	hash ^= b.enPassantKey()
+	return hash, pawnHash
+}
+
+// enPassantKey returns the key of the en passant target. It is only hashed when a pawn of the
+// side to move could capture there, otherwise the position is the same as without the target.
+func (b *Board) enPassantKey() uint64 {
+	if b.EnPassant == NoEnPassant || b.SideToMove > Black {
+		return 0
+	}
+	if PawnAttacks(b.EnPassant, b.SideToMove^1)&b.Pieces[b.SideToMove][Pawn] == 0 {
+		return 0
+	}
+	return zobristEnPassant[b.EnPassant.FileOf()]
+}
+
+// togglePieceKey adds or removes a piece on a square from the position keys.
+func (b *Board) togglePieceKey(cl Color, p Piece, sq Square) {
+	key := zobristPieces[cl][p][sq]
+	b.Hash ^= key
+	if p == Pawn {
+		b.PawnHash ^= key
+	}
+}
@@ -0,0 +1,141 @@
+package board
+
+import "testing"
+
+// playUCI plays moves given in UCI notation.
+func playUCI(t *testing.T, b *Board, moves ...string) {
+	t.Helper()
+	for _, s := range moves {
+		m, err := b.ParseUCIMove(s)
+		if err != nil {
+			t.Fatalf("Expected no error parsing %s, got %v instead", s, err)
+		}
+		if err := b.PlayMove(m); err != nil {
+			t.Fatalf("Expected no error playing %s, got %v instead", s, err)
+		}
+	}
+}
+
+func TestHashTranspositions(t *testing.T) {
+	var a, b Board
+	a.SetInitialBoard()
+	b.SetInitialBoard()
+	playUCI(t, &a, "g1f3", "g8f6", "b1c3", "b8c6")
+	playUCI(t, &b, "b1c3", "b8c6", "g1f3", "g8f6")
+	if a.Hash != b.Hash {
+		t.Errorf("expected transposed positions to share a hash, got %x and %x", a.Hash, b.Hash)
+	}
+
+	// Same pieces, other side to move
+	c, err := ParseFEN("r1bqkb1r/pppppppp/2n2n2/8/8/2N2N2/PPPPPPPP/R1BQKB1R b KQkq - 4 3")
+	if err != nil {
+		t.Fatalf("invalid test FEN: %v", err)
+	}
+	if c.Hash == a.Hash {
+		t.Errorf("expected side to move to change the hash")
+	}
+
+	// Knights went back and forth, losing no rights: the start position again
+	var start Board
+	start.SetInitialBoard()
+	playUCI(t, &a, "f3g1", "f6g8", "c3b1", "c6b8")
+	if a.Hash != start.Hash {
+		t.Errorf("expected hash of the start position %x, got %x instead", start.Hash, a.Hash)
+	}
+}
+
+func TestHashPositionState(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		other string
+		equal bool
+	}{
+		{
+			name:  "castling rights are hashed",
+			fen:   "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			other: "r3k2r/8/8/8/8/8/8/R3K2R w Kkq - 0 1",
+			equal: false,
+		},
+		{
+			name:  "en passant target without capturing pawn is ignored",
+			fen:   "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			other: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+			equal: true,
+		},
+		{
+			name:  "capturable en passant target is hashed",
+			fen:   "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2",
+			other: "4k3/8/8/3pP3/8/8/8/4K3 w - - 0 2",
+			equal: false,
+		},
+		{
+			name:  "move counters are ignored",
+			fen:   "4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+			other: "4k3/8/8/8/8/8/8/4K3 w - - 37 80",
+			equal: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			b, err := ParseFEN(tt.other)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.other, err)
+			}
+			if (a.Hash == b.Hash) != tt.equal {
+				t.Errorf("expected hashes equal to be %v, got %x and %x", tt.equal, a.Hash, b.Hash)
+			}
+		})
+	}
+}
+
+func TestPawnHash(t *testing.T) {
+	var b Board
+	b.SetInitialBoard()
+	start := b.PawnHash
+	playUCI(t, &b, "g1f3", "b8c6")
+	if b.PawnHash != start {
+		t.Errorf("expected piece moves to keep the pawn hash %x, got %x instead", start, b.PawnHash)
+	}
+	playUCI(t, &b, "e2e4")
+	if b.PawnHash == start {
+		t.Errorf("expected a pawn move to change the pawn hash")
+	}
+}
+
+// TestHashIncremental compares the incrementally updated keys with a full recomputation over a move tree.
+func TestHashIncremental(t *testing.T) {
+	for _, pos := range perftPositions {
+		t.Run(pos.name, func(t *testing.T) {
+			b, err := ParseFEN(pos.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", pos.fen, err)
+			}
+			checkHashTree(t, b, 3)
+		})
+	}
+}
+
+func checkHashTree(t *testing.T, b *Board, depth int) {
+	t.Helper()
+	if hash, pawnHash := b.computeHash(); hash != b.Hash || pawnHash != b.PawnHash {
+		t.Fatalf("incremental keys differ from recomputed ones in %s", b.ToFEN())
+	}
+	if depth == 0 {
+		return
+	}
+	var ml MoveList
+	b.GenerateLegalMoves(&ml)
+	for i := 0; i < ml.Count; i++ {
+		undo, err := b.MakeMove(ml.Moves[i])
+		if err != nil {
+			t.Fatalf("Expected no error making %v, got %v instead", ml.Moves[i], err)
+		}
+		checkHashTree(t, b, depth-1)
+		b.UnmakeMove(ml.Moves[i], undo)
+	}
+}
@@ -0,0 +1,200 @@
+// Package book reads opening books in the Polyglot .bin format.
+//
+// A book is a sequence of 16 byte big endian entries sorted by position key,
+// so the moves for a position are found with a binary search over the file.
+package book
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"sort"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// EntrySize is the size in bytes of a single book entry
+const EntrySize = 16
+
+// Entry is a single book record, a move suggested for the position with the given key
+type Entry struct {
+	Key    uint64 // Polyglot key of the position
+	Move   uint16 // move in Polyglot encoding
+	Weight uint16 // relative quality of the move, higher is better
+	Learn  uint32 // learning data, unused by the reader
+}
+
+// BookMove is a book entry decoded into a move for the position
+type BookMove struct {
+	Move   board.Move
+	Weight uint16
+}
+
+// Book gives access to the entries of a Polyglot book without loading it in memory
+type Book struct {
+	r     io.ReaderAt
+	count int64     // number of entries
+	close io.Closer // underlying file when opened by path, nil otherwise
+}
+
+// Open opens the Polyglot book stored at path.
+// The book must be closed with Close when no longer needed.
+func Open(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	bk, err := New(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("invalid book %s: %v", path, err)
+	}
+	bk.close = f
+	return bk, nil
+}
+
+// New reads a Polyglot book of the given size in bytes from r.
+func New(r io.ReaderAt, size int64) (*Book, error) {
+	if size%EntrySize != 0 {
+		return nil, fmt.Errorf("size %d is not a multiple of the entry size %d", size, EntrySize)
+	}
+	return &Book{r: r, count: size / EntrySize}, nil
+}
+
+// Close closes the book file, it is a no-op for books created with New
+func (bk *Book) Close() error {
+	if bk.close == nil {
+		return nil
+	}
+	return bk.close.Close()
+}
+
+// Len returns the number of entries in the book
+func (bk *Book) Len() int {
+	return int(bk.count)
+}
+
+// entry reads the i-th entry of the book
+func (bk *Book) entry(i int64) (Entry, error) {
+	var buf [EntrySize]byte
+	if _, err := bk.r.ReadAt(buf[:], i*EntrySize); err != nil {
+		return Entry{}, fmt.Errorf("reading book entry %d: %v", i, err)
+	}
+	return decodeEntry(buf[:]), nil
+}
+
+// Lookup returns all entries for the position key, in book order.
+func (bk *Book) Lookup(key uint64) ([]Entry, error) {
+	// Binary search for the first entry with a key not lower than the wanted one
+	var err error
+	first := sort.Search(int(bk.count), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		var e Entry
+		e, err = bk.entry(int64(i))
+		return e.Key >= key
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for i := int64(first); i < bk.count; i++ {
+		e, err := bk.entry(i)
+		if err != nil {
+			return nil, err
+		}
+		if e.Key != key {
+			break
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Moves returns the book moves for the position.
+// Entries that do not decode to a legal move, usually from key collisions, are skipped.
+func (bk *Book) Moves(b *board.Board) ([]BookMove, error) {
+	entries, err := bk.Lookup(Key(b))
+	if err != nil {
+		return nil, err
+	}
+	moves := make([]BookMove, 0, len(entries))
+	for _, e := range entries {
+		m, err := DecodeMove(b, e.Move)
+		if err != nil {
+			continue
+		}
+		moves = append(moves, BookMove{Move: m, Weight: e.Weight})
+	}
+	return moves, nil
+}
+
+// BestMove returns the book move with the highest weight, ok is false when the position is not in the book.
+func (bk *Book) BestMove(b *board.Board) (m board.Move, ok bool, err error) {
+	moves, err := bk.Moves(b)
+	if err != nil || len(moves) == 0 {
+		return board.Move{}, false, err
+	}
+	best := moves[0]
+	for _, bm := range moves[1:] {
+		if bm.Weight > best.Weight {
+			best = bm
+		}
+	}
+	return best.Move, true, nil
+}
+
+// RandomMove picks a book move with probability proportional to its weight, moves with zero weight are never picked.
+// A nil rng uses the default random source. ok is false when the position has no move to pick.
+func (bk *Book) RandomMove(b *board.Board, rng *rand.Rand) (m board.Move, ok bool, err error) {
+	moves, err := bk.Moves(b)
+	if err != nil {
+		return board.Move{}, false, err
+	}
+	total := 0
+	for _, bm := range moves {
+		total += int(bm.Weight)
+	}
+	if total == 0 {
+		return board.Move{}, false, nil
+	}
+
+	var pick int
+	if rng == nil {
+		pick = rand.IntN(total)
+	} else {
+		pick = rng.IntN(total)
+	}
+	for _, bm := range moves {
+		pick -= int(bm.Weight)
+		if pick < 0 {
+			return bm.Move, true, nil
+		}
+	}
+	panic("unreachable")
+}
+
+func decodeEntry(buf []byte) Entry {
+	return Entry{
+		Key:    binary.BigEndian.Uint64(buf[0:8]),
+		Move:   binary.BigEndian.Uint16(buf[8:10]),
+		Weight: binary.BigEndian.Uint16(buf[10:12]),
+		Learn:  binary.BigEndian.Uint32(buf[12:16]),
+	}
+}
+
+func encodeEntry(buf []byte, e Entry) {
+	binary.BigEndian.PutUint64(buf[0:8], e.Key)
+	binary.BigEndian.PutUint16(buf[8:10], e.Move)
+	binary.BigEndian.PutUint16(buf[10:12], e.Weight)
+	binary.BigEndian.PutUint32(buf[12:16], e.Learn)
+}
@@ -0,0 +1,156 @@
+package book
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// newTestBook builds an in memory book from the entries, sorting them by key like a real book.
+func newTestBook(t *testing.T, entries []Entry) *Book {
+	t.Helper()
+	data := encodeBook(entries)
+	bk, err := New(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	return bk
+}
+
+func encodeBook(entries []Entry) []byte {
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	data := make([]byte, len(sorted)*EntrySize)
+	for i, e := range sorted {
+		encodeEntry(data[i*EntrySize:], e)
+	}
+	return data
+}
+
+// startEntries has three moves for the starting position surrounded by unrelated keys
+func startEntries() []Entry {
+	var b board.Board
+	b.SetInitialBoard()
+	key := Key(&b)
+	return []Entry{
+		{Key: 1, Move: 12<<6 | 28, Weight: 50},
+		{Key: key, Move: 12<<6 | 28, Weight: 10},   // e2e4
+		{Key: key, Move: 11<<6 | 27, Weight: 30},   // d2d4
+		{Key: key, Move: 6<<6 | 21, Weight: 0},     // g1f3
+		{Key: key, Move: 12<<6 | 36, Weight: 1000}, // e2e5, illegal
+		{Key: ^uint64(0), Move: 11<<6 | 27, Weight: 50},
+	}
+}
+
+func TestLookup(t *testing.T) {
+	bk := newTestBook(t, startEntries())
+	if bk.Len() != 6 {
+		t.Errorf("expected 6 entries, got %d instead", bk.Len())
+	}
+	tests := []struct {
+		name string
+		key  uint64
+		want int
+	}{
+		{"first key", 1, 1},
+		{"last key", ^uint64(0), 1},
+		{"several entries", startEntries()[1].Key, 4},
+		{"missing key", 2, 0},
+		{"key below all entries", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := bk.Lookup(tt.key)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if len(entries) != tt.want {
+				t.Errorf("expected %d entries, got %d instead", tt.want, len(entries))
+			}
+			for _, e := range entries {
+				if e.Key != tt.key {
+					t.Errorf("expected entries with key %#x, got %#x", tt.key, e.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestMoves(t *testing.T) {
+	bk := newTestBook(t, startEntries())
+	var b board.Board
+	b.SetInitialBoard()
+
+	moves, err := bk.Moves(&b)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if len(moves) != 3 {
+		t.Fatalf("expected the 3 legal book moves, got %v instead", moves)
+	}
+
+	best, ok, err := bk.BestMove(&b)
+	if err != nil || !ok {
+		t.Fatalf("expected a best move, got ok %v and error %v instead", ok, err)
+	}
+	if want := findMove(t, &b, "d2d4"); best != want {
+		t.Errorf("expected best move %v, got %v instead", want, best)
+	}
+
+	// Only e2e4 and d2d4 have weight, d2d4 three times as often
+	rng := rand.New(rand.NewPCG(1, 2))
+	counts := map[board.Move]int{}
+	for i := 0; i < 4000; i++ {
+		m, ok, err := bk.RandomMove(&b, rng)
+		if err != nil || !ok {
+			t.Fatalf("expected a random move, got ok %v and error %v instead", ok, err)
+		}
+		counts[m]++
+	}
+	e4, d4 := counts[findMove(t, &b, "e2e4")], counts[findMove(t, &b, "d2d4")]
+	if e4+d4 != 4000 {
+		t.Errorf("expected only weighted moves to be picked, got %v", counts)
+	}
+	if d4 < 2700 || d4 > 3300 {
+		t.Errorf("expected d2d4 about 3000 times, got %d instead", d4)
+	}
+
+	// Positions outside the book have no moves
+	if err := b.PlayMove(findMove(t, &b, "a2a3")); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if _, ok, err := bk.BestMove(&b); ok || err != nil {
+		t.Errorf("expected no book move, got ok %v and error %v instead", ok, err)
+	}
+	if _, ok, err := bk.RandomMove(&b, nil); ok || err != nil {
+		t.Errorf("expected no book move, got ok %v and error %v instead", ok, err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.bin")
+	if err := os.WriteFile(path, encodeBook(startEntries()), 0o644); err != nil {
+		t.Fatalf("writing test book: %v", err)
+	}
+	bk, err := Open(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	defer bk.Close()
+	if bk.Len() != 6 {
+		t.Errorf("expected 6 entries, got %d instead", bk.Len())
+	}
+
+	// Truncated books are rejected
+	if err := os.WriteFile(path, encodeBook(startEntries())[:20], 0o644); err != nil {
+		t.Fatalf("writing test book: %v", err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Errorf("expected error for truncated book, got nil instead")
+	}
+}
@@ -0,0 +1,144 @@
+package book
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// Points given to a move for the result of the game, from the point of view of the side playing it
+const (
+	winPoints  = 2
+	drawPoints = 1
+)
+
+// moveStats accumulates how a move fared in the games it was played
+type moveStats struct {
+	games  int
+	points int
+}
+
+// Builder collects the moves played in a set of games and turns them into book entries.
+type Builder struct {
+	MaxPly   int // plies recorded from each game, 0 records whole games
+	MinGames int // moves played in fewer games are left out of the book
+
+	moves map[uint64]map[uint16]*moveStats // [position key][encoded move]
+}
+
+// NewBuilder returns a Builder recording up to maxPly plies per game and keeping moves played in at least minGames games.
+func NewBuilder(maxPly, minGames int) *Builder {
+	return &Builder{MaxPly: maxPly, MinGames: minGames, moves: make(map[uint64]map[uint16]*moveStats)}
+}
+
+// AddGame replays the game moves from the start position and records them with the game result,
+// one of "1-0", "0-1", "1/2-1/2" or "*". Unfinished games count moves without awarding points.
+// The start position is not modified.
+func (bd *Builder) AddGame(start *board.Board, moves []board.Move, result string) error {
+	var points [2]int // [Color]
+	switch result {
+	case "1-0":
+		points[board.White] = winPoints
+	case "0-1":
+		points[board.Black] = winPoints
+	case "1/2-1/2":
+		points = [2]int{drawPoints, drawPoints}
+	case "*":
+	default:
+		return fmt.Errorf("unknown game result %q", result)
+	}
+
+	// Replay the whole game first, so games with illegal moves leave no partial records
+	type played struct {
+		key  uint64
+		move uint16
+		side board.Color
+	}
+	var record []played
+	b := *start
+	for ply, m := range moves {
+		if bd.MaxPly > 0 && ply >= bd.MaxPly {
+			break
+		}
+		record = append(record, played{key: Key(&b), move: EncodeMove(&b, m), side: b.SideToMove})
+		if err := b.PlayMove(m); err != nil {
+			return fmt.Errorf("ply %d: %v", ply+1, err)
+		}
+	}
+
+	for _, p := range record {
+		byMove := bd.moves[p.key]
+		if byMove == nil {
+			byMove = make(map[uint16]*moveStats)
+			bd.moves[p.key] = byMove
+		}
+		stats := byMove[p.move]
+		if stats == nil {
+			stats = &moveStats{}
+			byMove[p.move] = stats
+		}
+		stats.games++
+		stats.points += points[p.side]
+	}
+	return nil
+}
+
+// Entries returns the book entries for the recorded moves played in at least MinGames games,
+// sorted by key and then by decreasing weight. Weights are the result points of each move,
+// scaled down when needed to fit the 16 bit weight field.
+func (bd *Builder) Entries() []Entry {
+	var entries []Entry
+	maxPoints := 0
+	for key, byMove := range bd.moves {
+		for move, stats := range byMove {
+			if stats.games < bd.MinGames {
+				continue
+			}
+			entries = append(entries, Entry{Key: key, Move: move})
+			maxPoints = max(maxPoints, stats.points)
+		}
+	}
+	for i := range entries {
+		points := bd.moves[entries[i].Key][entries[i].Move].points
+		if maxPoints > 0xffff {
+			points = int(int64(points) * 0xffff / int64(maxPoints))
+		}
+		entries[i].Weight = uint16(points)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		return a.Move < b.Move
+	})
+	return entries
+}
+
+// WriteTo writes the book built from the recorded games to w in Polyglot format.
+func (bd *Builder) WriteTo(w io.Writer) (int64, error) {
+	return Write(w, bd.Entries())
+}
+
+// Write writes the entries to w in Polyglot format, they must already be sorted by key.
+func Write(w io.Writer, entries []Entry) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var buf [EntrySize]byte
+	var n int64
+	for _, e := range entries {
+		encodeEntry(buf[:], e)
+		written, err := bw.Write(buf[:])
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
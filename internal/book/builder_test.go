@@ -0,0 +1,102 @@
+package book
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// playLine converts "e2e4" style moves into board moves played from the starting position.
+func playLine(t *testing.T, line ...string) []board.Move {
+	t.Helper()
+	var b board.Board
+	b.SetInitialBoard()
+	moves := make([]board.Move, 0, len(line))
+	for _, s := range line {
+		m := findMove(t, &b, s)
+		if err := b.PlayMove(m); err != nil {
+			t.Fatalf("Expected no error playing %s, got %v instead", s, err)
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+func TestBuilder(t *testing.T) {
+	var start board.Board
+	start.SetInitialBoard()
+
+	bd := NewBuilder(2, 2)
+	games := []struct {
+		moves  []board.Move
+		result string
+	}{
+		{playLine(t, "e2e4", "e7e5", "g1f3"), "1-0"},
+		{playLine(t, "e2e4", "e7e5", "f1c4"), "1/2-1/2"},
+		{playLine(t, "e2e4", "c7c5"), "0-1"},
+		{playLine(t, "d2d4", "d7d5"), "1-0"}, // played once, filtered out
+		{playLine(t, "e2e4", "e7e5"), "*"},
+	}
+	for _, g := range games {
+		if err := bd.AddGame(&start, g.moves, g.result); err != nil {
+			t.Fatalf("Expected no error, got %v instead", err)
+		}
+	}
+
+	// e2e4 in 4 games: 2+1+0+0 points, e7e5 in 3 games: 0+1+0 points
+	entries := bd.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v instead", entries)
+	}
+	afterE4 := start
+	if err := afterE4.PlayMove(findMove(t, &afterE4, "e2e4")); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	want := map[uint64]Entry{
+		Key(&start):   {Key: Key(&start), Move: 12<<6 | 28, Weight: 3},
+		Key(&afterE4): {Key: Key(&afterE4), Move: 52<<6 | 36, Weight: 1},
+	}
+	for i, e := range entries {
+		if e != want[e.Key] {
+			t.Errorf("expected entry %+v, got %+v instead", want[e.Key], e)
+		}
+		if i > 0 && entries[i-1].Key > e.Key {
+			t.Errorf("expected entries sorted by key")
+		}
+	}
+
+	// The written book finds the recorded moves again
+	var buf bytes.Buffer
+	n, err := bd.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if n != int64(len(entries)*EntrySize) {
+		t.Errorf("expected %d bytes written, got %d instead", len(entries)*EntrySize, n)
+	}
+	bk, err := New(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	m, ok, err := bk.BestMove(&start)
+	if err != nil || !ok {
+		t.Fatalf("expected a book move, got ok %v and error %v instead", ok, err)
+	}
+	if want := findMove(t, &start, "e2e4"); m != want {
+		t.Errorf("expected book move %v, got %v instead", want, m)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	var start board.Board
+	start.SetInitialBoard()
+	bd := NewBuilder(0, 1)
+	if err := bd.AddGame(&start, playLine(t, "e2e4"), "2-0"); err == nil {
+		t.Errorf("expected error for unknown result, got nil instead")
+	}
+	illegal := []board.Move{{From: board.Square(52), To: board.Square(36)}} // e7e5 with White to move
+	if err := bd.AddGame(&start, illegal, "1-0"); err == nil {
+		t.Errorf("expected error for move of the wrong side, got nil instead")
+	}
+}
@@ -0,0 +1,94 @@
+package book
+
+import (
+	"fmt"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// Offsets of each group of keys inside polyglotRandom
+const (
+	randomPiece     = 0
+	randomCastle    = 768
+	randomEnPassant = 772
+	randomTurn      = 780
+)
+
+// Key computes the Polyglot key of the position, which indexes its entries in a book.
+// It uses the Polyglot random table, so it differs from the engine's own board.Board Hash.
+func Key(b *board.Board) uint64 {
+	var key uint64
+	for sq := board.Square(0); sq < 64; sq++ {
+		color, piece := b.PieceAt(sq)
+		if piece == board.Empty {
+			continue
+		}
+		// Pieces alternate black and white: black pawn, white pawn, black knight...
+		kind := 2 * int(piece-board.Pawn)
+		if color == board.White {
+			kind++
+		}
+		key ^= polyglotRandom[randomPiece+64*kind+int(sq)]
+	}
+
+	castling := []board.CastlingRights{board.WhiteKingSide, board.WhiteQueenSide, board.BlackKingSide, board.BlackQueenSide}
+	for i, right := range castling {
+		if b.CastlingRights&right != 0 {
+			key ^= polyglotRandom[randomCastle+i]
+		}
+	}
+
+	// The en passant file only counts when a pawn of the side to move stands next to the pushed pawn
+	if b.EnPassant != board.NoEnPassant {
+		if board.PawnAttacks(b.EnPassant, b.SideToMove^1)&b.Pieces[b.SideToMove][board.Pawn] != 0 {
+			key ^= polyglotRandom[randomEnPassant+b.EnPassant.FileOf()]
+		}
+	}
+
+	if b.SideToMove == board.White {
+		key ^= polyglotRandom[randomTurn]
+	}
+	return key
+}
+
+// Polyglot moves pack the target square in bits 0-5, the source square in bits 6-11
+// and the promotion piece in bits 12-14 (0 none, 1 knight, 2 bishop, 3 rook, 4 queen).
+// Squares use the same 0=a1..63=h8 numbering as board.Square.
+
+// EncodeMove converts a move of the position into the Polyglot encoding.
+// Castling is written as the king capturing its own rook, e1h1 instead of e1g1.
+// Chess960 castling moves already name the rook square and are kept as they are.
+func EncodeMove(b *board.Board, m board.Move) uint16 {
+	to := m.To
+	if m.Type.Has(board.Castle) && !b.Chess960 {
+		rookFile := 7
+		if m.To.FileOf() < m.From.FileOf() {
+			rookFile = 0
+		}
+		to = board.Square(m.From.RankOf()*8 + rookFile)
+	}
+	var promotion uint16
+	if m.Type.Has(board.Promotion) {
+		promotion = uint16(m.Promotion-board.Knight) + 1
+	}
+	return uint16(to) | uint16(m.From)<<6 | promotion<<12
+}
+
+// DecodeMove converts a Polyglot move into the legal move of the position it stands for.
+func DecodeMove(b *board.Board, pm uint16) (board.Move, error) {
+	to := board.Square(pm & 0x3f)
+	from := board.Square(pm >> 6 & 0x3f)
+	uci := from.String() + to.String()
+	if p := pm >> 12 & 0x7; p != 0 {
+		if p > 4 {
+			return board.Move{}, fmt.Errorf("invalid promotion %d in book move %#04x", p, pm)
+		}
+		uci += string("nbrq"[p-1])
+	}
+	// UCI parsing also understands castling written as the king taking its own rook
+	m, err := b.ParseUCIMove(uci)
+	if err != nil {
+		return board.Move{}, fmt.Errorf("book move %#04x: %v", pm, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,97 @@
+package book
+
+import (
+	"testing"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// findMove returns the legal move written in UCI notation.
+func findMove(t *testing.T, b *board.Board, s string) board.Move {
+	t.Helper()
+	m, err := b.ParseUCIMove(s)
+	if err != nil {
+		t.Fatalf("Expected no error parsing %s, got %v instead", s, err)
+	}
+	return m
+}
+
+func TestKey(t *testing.T) {
+	// Reference keys from the Polyglot book format specification
+	tests := []struct {
+		name  string
+		moves []string
+		key   uint64
+	}{
+		{"starting position", nil, 0x463b96181691fc9c},
+		{"e2e4", []string{"e2e4"}, 0x823c9b50fd114196},
+		{"e2e4 d7d5", []string{"e2e4", "d7d5"}, 0x0756b94461c50fb0},
+		{"e2e4 d7d5 e4e5", []string{"e2e4", "d7d5", "e4e5"}, 0x662fafb965db29d4},
+		{"e2e4 d7d5 e4e5 f7f5", []string{"e2e4", "d7d5", "e4e5", "f7f5"}, 0x22a48b5a8e47ff78},
+		{"e2e4 d7d5 e4e5 f7f5 e1e2", []string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2"}, 0x652a607ca3f242c1},
+		{"e2e4 d7d5 e4e5 f7f5 e1e2 e8f7", []string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2", "e8f7"}, 0x00fdd303c946bdd9},
+		{"a2a4 b7b5 h2h4 b5b4 c2c4", []string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4"}, 0x3c8123ea7b067637},
+		{"a2a4 b7b5 h2h4 b5b4 c2c4 b4c3 a1a3", []string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4", "b4c3", "a1a3"}, 0x5c3f9b829b279560},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b board.Board
+			b.SetInitialBoard()
+			for _, s := range tt.moves {
+				if err := b.PlayMove(findMove(t, &b, s)); err != nil {
+					t.Fatalf("Expected no error playing %s, got %v instead", s, err)
+				}
+			}
+			if key := Key(&b); key != tt.key {
+				t.Errorf("expected key %#016x, got %#016x instead", tt.key, key)
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		fen     string
+		move    string
+		encoded uint16
+	}{
+		{"pawn push", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e4", 12<<6 | 28},
+		{"white kingside castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", 4<<6 | 7},
+		{"white queenside castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1c1", 4<<6 | 0},
+		{"black kingside castling", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8g8", 60<<6 | 63},
+		{"chess960 castling with an inner rook", "4k3/8/8/8/8/8/8/1RK3RR w GB - 0 1", "c1g1", 2<<6 | 6},
+		{"chess960 castling with the king on the e-file", "4k3/8/8/8/8/8/8/1R2K1R1 w GB - 0 1", "e1g1", 4<<6 | 6},
+		{"chess960 queenside castling with the king on the e-file", "4k3/8/8/8/8/8/8/1R2K1R1 w GB - 0 1", "e1b1", 4<<6 | 1},
+		{"queen promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8q", 4<<12 | 52<<6 | 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := board.ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			m := findMove(t, b, tt.move)
+			if got := EncodeMove(b, m); got != tt.encoded {
+				t.Errorf("expected encoding %#04x, got %#04x instead", tt.encoded, got)
+			}
+			decoded, err := DecodeMove(b, tt.encoded)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if decoded != m {
+				t.Errorf("expected decoded move %v, got %v instead", m, decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeMoveError(t *testing.T) {
+	var b board.Board
+	b.SetInitialBoard()
+	for _, pm := range []uint16{12<<6 | 36, 1<<6 | 7, 5<<12 | 12<<6 | 28} { // e2e5, b1h1, bad promotion
+		if m, err := DecodeMove(&b, pm); err == nil {
+			t.Errorf("DecodeMove(%#04x) expected error, got %v instead", pm, m)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Package clock implements chess clocks for the usual time controls:
+// sudden death, Fischer increment, simple and Bronstein delay, multiple periods and hourglass.
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// ErrFlagFall is returned when a player presses the clock after running out of time
+var ErrFlagFall = errors.New("flag fell")
+
+// Clock keeps the time left for both players, only one of them running at a time
+type Clock struct {
+	control Control
+	src     Source
+
+	remaining [2]time.Duration // [Color] time left, not counting the current turn
+	moves     [2]int           // [Color] moves completed in the current period
+	period    [2]int           // [Color] index of the current period
+	running   board.Color      // player whose time is running, board.None when stopped
+	started   time.Time        // when the running player's turn began
+	flagged   board.Color      // player who ran out of time, board.None if nobody did
+}
+
+// New returns a stopped clock for the time control. A nil source reads the system time.
+func New(control Control, src Source) (*Clock, error) {
+	if len(control.Periods) == 0 {
+		return nil, fmt.Errorf("time control without periods")
+	}
+	for i, p := range control.Periods {
+		if p.Time < 0 || p.Increment < 0 || p.Delay < 0 || p.Moves < 0 {
+			return nil, fmt.Errorf("period %d has negative values", i+1)
+		}
+		if p.Moves == 0 && i != len(control.Periods)-1 {
+			return nil, fmt.Errorf("period %d has no move count but is not the last one", i+1)
+		}
+	}
+	if src == nil {
+		src = systemSource{}
+	}
+	first := control.Periods[0].Time
+	return &Clock{
+		control:   control,
+		src:       src,
+		remaining: [2]time.Duration{first, first},
+		running:   board.None,
+		flagged:   board.None,
+	}, nil
+}
+
+// Control returns the time control the clock was created with
+func (c *Clock) Control() Control {
+	return c.control
+}
+
+// Start begins the turn of the player, stopping the other clock without pressing it.
+// It does nothing once a flag has fallen.
+func (c *Clock) Start(color board.Color) {
+	if _, ok := c.Flagged(); ok {
+		return
+	}
+	c.Stop()
+	c.running = color
+	c.started = c.src.Now()
+}
+
+// Stop charges the running player for the time used so far and stops the clock,
+// no increment is added. Used when the game ends or is adjourned.
+func (c *Clock) Stop() {
+	if _, ok := c.Flagged(); ok || c.running == board.None {
+		return
+	}
+	c.remaining[c.running] -= c.charged(c.running, c.elapsed())
+	c.running = board.None
+}
+
+// Running returns the player whose time is running, ok is false when the clock is stopped
+func (c *Clock) Running() (color board.Color, ok bool) {
+	return c.running, c.running != board.None
+}
+
+// Remaining returns the time the player has left at this moment
+func (c *Clock) Remaining(color board.Color) time.Duration {
+	left := c.remaining[color]
+	if color == c.running {
+		left -= c.charged(color, c.elapsed())
+	}
+	return max(0, left)
+}
+
+// Flagged returns the player who ran out of time, ok is false while both have time left
+func (c *Clock) Flagged() (color board.Color, ok bool) {
+	if c.flagged == board.None && c.running != board.None && c.Remaining(c.running) == 0 {
+		c.flagged = c.running
+		c.remaining[c.running] = 0
+		c.running = board.None
+	}
+	return c.flagged, c.flagged != board.None
+}
+
+// Press ends the turn of the running player, adding increments, delays and new periods,
+// and starts the opponent's clock. It returns ErrFlagFall if the player had run out of time.
+func (c *Clock) Press() error {
+	if _, ok := c.Flagged(); ok {
+		return ErrFlagFall
+	}
+	color := c.running
+	if color == board.None {
+		return fmt.Errorf("clock is not running")
+	}
+
+	p := c.control.Periods[c.period[color]]
+	elapsed := c.elapsed()
+	charged := c.charged(color, elapsed)
+	c.remaining[color] -= charged
+	if p.DelayMode == BronsteinDelay {
+		c.remaining[color] += min(elapsed, p.Delay)
+	}
+	c.remaining[color] += p.Increment
+	if c.control.Hourglass {
+		c.remaining[color^1] += charged
+	}
+
+	// Completing the moves of a period adds the time of the next one, the last period repeats
+	c.moves[color]++
+	if p.Moves > 0 && c.moves[color] == p.Moves {
+		c.moves[color] = 0
+		if c.period[color] < len(c.control.Periods)-1 {
+			c.period[color]++
+		}
+		c.remaining[color] += c.control.Periods[c.period[color]].Time
+	}
+
+	c.running = color ^ 1
+	c.started = c.src.Now()
+	return nil
+}
+
+// elapsed returns the time the running player has spent on the current turn
+func (c *Clock) elapsed() time.Duration {
+	return c.src.Now().Sub(c.started)
+}
+
+// charged returns how much of the turn counts against the player, a simple delay is free
+func (c *Clock) charged(color board.Color, elapsed time.Duration) time.Duration {
+	p := c.control.Periods[c.period[color]]
+	if p.DelayMode == SimpleDelay {
+		return max(0, elapsed-p.Delay)
+	}
+	return elapsed
+}
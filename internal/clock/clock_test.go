@@ -0,0 +1,134 @@
+package clock
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// newClock returns a clock for the control driven by a manual source
+func newClock(t *testing.T, control Control) (*Clock, *Manual) {
+	t.Helper()
+	src := NewManual(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	c, err := New(control, src)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	return c, src
+}
+
+func TestPress(t *testing.T) {
+	const s = time.Second
+	tests := []struct {
+		name    string
+		control Control
+		thinks  []time.Duration // time used by each move, alternating from White
+		white   time.Duration
+		black   time.Duration
+	}{
+		{"sudden death", SuddenDeath(60 * s), []time.Duration{10 * s, 5 * s, 10 * s}, 40 * s, 55 * s},
+		{"fischer increment", Fischer(60*s, 2*s), []time.Duration{10 * s, 5 * s, 10 * s}, 44 * s, 57 * s},
+		{"simple delay under the delay", Delay(60*s, 5*s), []time.Duration{3 * s, 5 * s}, 60 * s, 60 * s},
+		{"simple delay over the delay", Delay(60*s, 5*s), []time.Duration{8 * s, 20 * s}, 57 * s, 45 * s},
+		{"bronstein under the delay", Bronstein(60*s, 5*s), []time.Duration{3 * s, 5 * s}, 60 * s, 60 * s},
+		{"bronstein over the delay", Bronstein(60*s, 5*s), []time.Duration{8 * s, 20 * s}, 57 * s, 45 * s},
+		{"hourglass", Hourglass(60 * s), []time.Duration{10 * s, 4 * s, 1 * s}, 53 * s, 67 * s},
+		{
+			"multiple periods",
+			MultiPeriod(Period{Moves: 2, Time: 60 * s}, Period{Time: 30 * s, Increment: s}),
+			[]time.Duration{10 * s, 10 * s, 10 * s, 10 * s, 10 * s},
+			61 * s, 70 * s,
+		},
+		{
+			"repeating last period",
+			MultiPeriod(Period{Moves: 1, Time: 60 * s}, Period{Moves: 2, Time: 10 * s}),
+			[]time.Duration{10 * s, 0, 10 * s, 0, 10 * s, 0, 10 * s},
+			40 * s, 80 * s,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, src := newClock(t, tt.control)
+			c.Start(board.White)
+			for i, d := range tt.thinks {
+				src.Advance(d)
+				if err := c.Press(); err != nil {
+					t.Fatalf("press %d: Expected no error, got %v instead", i+1, err)
+				}
+			}
+			if got := c.Remaining(board.White); got != tt.white {
+				t.Errorf("expected White to have %v, got %v instead", tt.white, got)
+			}
+			if got := c.Remaining(board.Black); got != tt.black {
+				t.Errorf("expected Black to have %v, got %v instead", tt.black, got)
+			}
+		})
+	}
+}
+
+func TestRemainingWhileRunning(t *testing.T) {
+	c, src := newClock(t, Delay(time.Minute, 5*time.Second))
+	c.Start(board.White)
+	src.Advance(3 * time.Second)
+	if got := c.Remaining(board.White); got != time.Minute {
+		t.Errorf("expected the delay to keep the time at %v, got %v instead", time.Minute, got)
+	}
+	src.Advance(7 * time.Second)
+	if got := c.Remaining(board.White); got != 55*time.Second {
+		t.Errorf("expected %v after the delay, got %v instead", 55*time.Second, got)
+	}
+	if color, ok := c.Running(); !ok || color != board.White {
+		t.Errorf("expected White's clock to be running, got %v, %v instead", color, ok)
+	}
+
+	c.Stop()
+	src.Advance(time.Minute)
+	if _, ok := c.Running(); ok {
+		t.Errorf("expected the clock to be stopped")
+	}
+	if got := c.Remaining(board.White); got != 55*time.Second {
+		t.Errorf("expected a stopped clock to keep %v, got %v instead", 55*time.Second, got)
+	}
+}
+
+func TestFlagFall(t *testing.T) {
+	c, src := newClock(t, Fischer(10*time.Second, 5*time.Second))
+	c.Start(board.White)
+	src.Advance(time.Second)
+	if err := c.Press(); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if _, ok := c.Flagged(); ok {
+		t.Fatalf("expected no flag fall yet")
+	}
+	src.Advance(10 * time.Second)
+	color, ok := c.Flagged()
+	if !ok || color != board.Black {
+		t.Fatalf("expected Black's flag to fall, got %v, %v instead", color, ok)
+	}
+	if err := c.Press(); !errors.Is(err, ErrFlagFall) {
+		t.Errorf("expected ErrFlagFall, got %v instead", err)
+	}
+	if got := c.Remaining(board.Black); got != 0 {
+		t.Errorf("expected no time left, got %v instead", got)
+	}
+	// The flag stays down, the clock cannot be restarted
+	c.Start(board.White)
+	if _, ok := c.Running(); ok {
+		t.Errorf("expected the clock to stay stopped after the flag fall")
+	}
+}
+
+func TestNewInvalidControl(t *testing.T) {
+	for name, control := range map[string]Control{
+		"no periods":              {},
+		"negative time":           SuddenDeath(-time.Second),
+		"open period before last": MultiPeriod(Period{Time: time.Minute}, Period{Time: time.Minute}),
+	} {
+		if _, err := New(control, nil); err == nil {
+			t.Errorf("%s: expected error, got nil instead", name)
+		}
+	}
+}
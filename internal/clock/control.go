@@ -0,0 +1,59 @@
+package clock
+
+import "time"
+
+// DelayMode selects how a per move delay is applied
+type DelayMode uint8
+
+const (
+	SimpleDelay    DelayMode = iota // the clock waits for the delay before it starts running
+	BronsteinDelay                  // the clock runs at once, the time used up to the delay is given back after the move
+)
+
+// Period is a stage of a time control, like the first 40 moves in a 40/90+30 control
+type Period struct {
+	Moves     int           // moves to play in the period, 0 for the rest of the game
+	Time      time.Duration // time added when the period starts
+	Increment time.Duration // Fischer increment added after every move
+	Delay     time.Duration // delay granted on every move
+	DelayMode DelayMode
+}
+
+// Control describes the time each player gets
+type Control struct {
+	Periods   []Period // stages in order, the last one repeats when it has a move count
+	Hourglass bool     // time used by a player is added to the opponent
+}
+
+// SuddenDeath gives each player a fixed time for the whole game
+func SuddenDeath(base time.Duration) Control {
+	return Control{Periods: []Period{{Time: base}}}
+}
+
+// Fischer adds an increment after every move
+func Fischer(base, increment time.Duration) Control {
+	return Control{Periods: []Period{{Time: base, Increment: increment}}}
+}
+
+// Delay waits before running the clock on every move, a simple or US delay
+func Delay(base, delay time.Duration) Control {
+	return Control{Periods: []Period{{Time: base, Delay: delay, DelayMode: SimpleDelay}}}
+}
+
+// Bronstein gives back the time used on every move, up to the delay
+func Bronstein(base, delay time.Duration) Control {
+	return Control{Periods: []Period{{Time: base, Delay: delay, DelayMode: BronsteinDelay}}}
+}
+
+// Hourglass starts both players with the time and moves the time one uses to the other
+func Hourglass(base time.Duration) Control {
+	return Control{Periods: []Period{{Time: base}}, Hourglass: true}
+}
+
+// MultiPeriod chains several stages, for example 40 moves in 90 minutes then 30 minutes for the rest:
+//
+//	MultiPeriod(Period{Moves: 40, Time: 90 * time.Minute, Increment: 30 * time.Second},
+//		Period{Time: 30 * time.Minute, Increment: 30 * time.Second})
+func MultiPeriod(periods ...Period) Control {
+	return Control{Periods: periods}
+}
@@ -0,0 +1,34 @@
+package clock
+
+import "time"
+
+// Source tells the clock the current time
+type Source interface {
+	Now() time.Time
+}
+
+// systemSource reads the system clock
+type systemSource struct{}
+
+func (systemSource) Now() time.Time {
+	return time.Now()
+}
+
+// Manual is a Source whose time only moves when told to, for tests and replaying recorded games
+type Manual struct {
+	now time.Time
+}
+
+// NewManual returns a Manual source set at the given time
+func NewManual(t time.Time) *Manual {
+	return &Manual{now: t}
+}
+
+func (m *Manual) Now() time.Time {
+	return m.now
+}
+
+// Advance moves the time of the source forward
+func (m *Manual) Advance(d time.Duration) {
+	m.now = m.now.Add(d)
+}
@@ -0,0 +1,166 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+var (
+	ErrNoDrawClaim = errors.New("no draw can be claimed")  // ClaimDraw without repetition or fifty moves
+	ErrNoDrawOffer = errors.New("no draw offer to accept") // answering a draw offer the opponent did not make
+)
+
+// EventKind identifies something that happened in a game besides a move
+type EventKind uint8
+
+const (
+	DrawOffered  EventKind = iota // a player offered a draw
+	DrawDeclined                  // the opponent declined the offer, also by moving
+	DrawAccepted                  // the opponent accepted the offer, ending the game
+	DrawClaimed                   // a player claimed a draw by repetition or the fifty-move rule
+	Resigned                      // a player resigned
+	FlagFell                      // a player ran out of time
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case DrawOffered:
+		return "draw offered"
+	case DrawDeclined:
+		return "draw declined"
+	case DrawAccepted:
+		return "draw accepted"
+	case DrawClaimed:
+		return "draw claimed"
+	case Resigned:
+		return "resigned"
+	case FlagFell:
+		return "flag fell"
+	}
+	return "unknown event"
+}
+
+// Event is a game event and the player behind it
+type Event struct {
+	Ply   int         // number of half moves played when it happened
+	Color board.Color // player who acted
+	Kind  EventKind
+}
+
+// Events returns the game events in the order they happened
+func (g *Game) Events() []Event {
+	return append([]Event(nil), g.events...)
+}
+
+// claimableDraw returns the rule a draw can be claimed by in the current position
+func (g *Game) claimableDraw() (Reason, bool) {
+	switch {
+	case g.Repetitions() >= 3:
+		return ThreefoldRepetition, true
+	case g.board.HalfMoveClock >= fiftyMovePlies:
+		return FiftyMoveRule, true
+	}
+	return NoReason, false
+}
+
+// CanClaimDraw checks if the player to move can claim a draw by threefold repetition or the fifty-move rule
+func (g *Game) CanClaimDraw() bool {
+	if g.Outcome().Result != InProgress {
+		return false
+	}
+	_, ok := g.claimableDraw()
+	return ok
+}
+
+// ClaimDraw ends the game in a draw when CanClaimDraw allows it
+func (g *Game) ClaimDraw() error {
+	if g.over() {
+		return ErrGameOver
+	}
+	reason, ok := g.claimableDraw()
+	if !ok {
+		return ErrNoDrawClaim
+	}
+	g.finish(g.board.SideToMove, DrawClaimed, Outcome{Result: Draw, Reason: reason})
+	return nil
+}
+
+// OfferDraw records a draw offer by the player, standing until the opponent answers or moves
+func (g *Game) OfferDraw(color board.Color) error {
+	if err := g.checkEvent(color); err != nil {
+		return err
+	}
+	if g.drawOffer != board.None {
+		return fmt.Errorf("a draw offer is already pending")
+	}
+	g.drawOffer = color
+	g.events = append(g.events, Event{Ply: g.Ply(), Color: color, Kind: DrawOffered})
+	return nil
+}
+
+// DrawOffer returns the player with a pending draw offer, ok is false when there is none
+func (g *Game) DrawOffer() (color board.Color, ok bool) {
+	return g.drawOffer, g.drawOffer != board.None
+}
+
+// AcceptDraw ends the game in a draw by agreement, accepting the opponent's offer
+func (g *Game) AcceptDraw(color board.Color) error {
+	if err := g.checkEvent(color); err != nil {
+		return err
+	}
+	if g.drawOffer != color^1 {
+		return ErrNoDrawOffer
+	}
+	g.finish(color, DrawAccepted, Outcome{Result: Draw, Reason: DrawAgreement})
+	return nil
+}
+
+// DeclineDraw rejects the opponent's draw offer
+func (g *Game) DeclineDraw(color board.Color) error {
+	if err := g.checkEvent(color); err != nil {
+		return err
+	}
+	if g.drawOffer != color^1 {
+		return ErrNoDrawOffer
+	}
+	g.drawOffer = board.None
+	g.events = append(g.events, Event{Ply: g.Ply(), Color: color, Kind: DrawDeclined})
+	return nil
+}
+
+// Resign ends the game with a win for the opponent of the player
+func (g *Game) Resign(color board.Color) error {
+	if err := g.checkEvent(color); err != nil {
+		return err
+	}
+	winner := WhiteWins
+	if color == board.White {
+		winner = BlackWins
+	}
+	g.finish(color, Resigned, Outcome{Result: winner, Reason: Resignation})
+	return nil
+}
+
+// checkEvent validates the player of an event, which can only happen in a game in progress
+func (g *Game) checkEvent(color board.Color) error {
+	if color != board.White && color != board.Black {
+		return fmt.Errorf("invalid player %v", color)
+	}
+	if g.over() {
+		return ErrGameOver
+	}
+	return nil
+}
+
+// finish ends the game at the current position with the outcome of the event.
+// Earlier positions are not affected, going back and playing a variation continues the game.
+func (g *Game) finish(color board.Color, kind EventKind, o Outcome) {
+	g.cur.end = o
+	g.drawOffer = board.None
+	if g.clock != nil {
+		g.clock.Stop()
+	}
+	g.events = append(g.events, Event{Ply: g.Ply(), Color: color, Kind: kind})
+}
@@ -0,0 +1,133 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+func TestClaimDraw(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+		want  Reason
+	}{
+		{
+			"threefold repetition", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			[]string{"Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1", "Ng8"},
+			ThreefoldRepetition,
+		},
+		{"fifty-move rule", "4k3/8/8/8/8/8/4P3/R3K3 w - - 99 80", []string{"Ra2"}, FiftyMoveRule},
+		{"twofold repetition", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", []string{"Nf3", "Nf6", "Ng1", "Ng8"}, NoReason},
+		{"forty-nine moves", "4k3/8/8/8/8/8/4P3/R3K3 w - - 97 80", []string{"Ra2"}, NoReason},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewFromFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			playSAN(t, g, tt.moves...)
+			if got := g.CanClaimDraw(); got != (tt.want != NoReason) {
+				t.Fatalf("expected CanClaimDraw %v, got %v instead", tt.want != NoReason, got)
+			}
+			err = g.ClaimDraw()
+			if tt.want == NoReason {
+				if !errors.Is(err, ErrNoDrawClaim) {
+					t.Errorf("expected ErrNoDrawClaim, got %v instead", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v instead", err)
+			}
+			if want := (Outcome{Draw, tt.want}); g.Outcome() != want {
+				t.Errorf("expected %v, got %v instead", want, g.Outcome())
+			}
+			if g.CanClaimDraw() || g.PlayUCI("a2a3") != ErrGameOver {
+				t.Errorf("expected the claimed draw to end the game")
+			}
+		})
+	}
+}
+
+func TestDrawOffer(t *testing.T) {
+	g := New()
+	playSAN(t, g, "e4")
+	if err := g.OfferDraw(board.White); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if c, ok := g.DrawOffer(); !ok || c != board.White {
+		t.Errorf("expected a pending offer by White, got %v (%v) instead", c, ok)
+	}
+	if err := g.OfferDraw(board.Black); err == nil {
+		t.Errorf("expected error for a second offer, got nil instead")
+	}
+	if err := g.AcceptDraw(board.White); !errors.Is(err, ErrNoDrawOffer) {
+		t.Errorf("expected ErrNoDrawOffer accepting an own offer, got %v instead", err)
+	}
+
+	// Black moves instead of answering, declining the offer
+	playSAN(t, g, "e5")
+	if _, ok := g.DrawOffer(); ok {
+		t.Errorf("expected the offer to lapse after Black moved")
+	}
+
+	// An explicit decline, then an accepted offer
+	if err := g.OfferDraw(board.Black); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if err := g.DeclineDraw(board.White); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if err := g.AcceptDraw(board.White); !errors.Is(err, ErrNoDrawOffer) {
+		t.Errorf("expected ErrNoDrawOffer after declining, got %v instead", err)
+	}
+	playSAN(t, g, "Nf3")
+	if err := g.OfferDraw(board.White); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if err := g.AcceptDraw(board.Black); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if want := (Outcome{Draw, DrawAgreement}); g.Outcome() != want {
+		t.Errorf("expected %v, got %v instead", want, g.Outcome())
+	}
+
+	want := []Event{
+		{Ply: 1, Color: board.White, Kind: DrawOffered},
+		{Ply: 2, Color: board.Black, Kind: DrawDeclined},
+		{Ply: 2, Color: board.Black, Kind: DrawOffered},
+		{Ply: 2, Color: board.White, Kind: DrawDeclined},
+		{Ply: 3, Color: board.White, Kind: DrawOffered},
+		{Ply: 3, Color: board.Black, Kind: DrawAccepted},
+	}
+	if !reflect.DeepEqual(g.Events(), want) {
+		t.Errorf("expected events %v, got %v instead", want, g.Events())
+	}
+}
+
+func TestResign(t *testing.T) {
+	g := New()
+	playSAN(t, g, "e4")
+	if err := g.Resign(board.Black); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if want := (Outcome{WhiteWins, Resignation}); g.Outcome() != want {
+		t.Errorf("expected %v, got %v instead", want, g.Outcome())
+	}
+	if g.PGN().Result != "1-0" {
+		t.Errorf("expected PGN result 1-0, got %s instead", g.PGN().Result)
+	}
+	for _, err := range []error{g.Resign(board.White), g.OfferDraw(board.White), g.ClaimDraw(), g.PlaySAN("e5")} {
+		if !errors.Is(err, ErrGameOver) {
+			t.Errorf("expected ErrGameOver, got %v instead", err)
+		}
+	}
+	if err := New().Resign(board.None); err == nil {
+		t.Errorf("expected error for an invalid player, got nil instead")
+	}
+}
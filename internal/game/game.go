@@ -0,0 +1,341 @@
+// Package game keeps track of a chess game: the moves played from its starting position,
+// their SAN, the PGN tags and how the game ended.
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+	"github.com/deadpyxel/cheesy/internal/clock"
+	"github.com/deadpyxel/cheesy/internal/pgn"
+)
+
+// Plies without captures or pawn moves for the move count draw rules
+const (
+	fiftyMovePlies       = 100
+	seventyFiveMovePlies = 150
+)
+
+// ErrGameOver is returned when playing a move in a finished game
+var ErrGameOver = errors.New("game is over")
+
+// Game is a chess game played from a starting position. Its moves form a tree, the main line
+// and its variations, with a cursor on the current position that can move back and forth.
+type Game struct {
+	pgn.Tags // PGN tag pairs describing the game
+
+	start board.Board
+	board board.Board // position at the cursor
+	root  *node       // starting position
+	cur   *node       // cursor
+
+	// Moves from the start to the cursor, with what is needed to take them back
+	path   []*node
+	undos  []board.Undo
+	hashes []uint64 // position keys before each move and after the last one
+
+	drawOffer board.Color // player with a pending draw offer, board.None if there is none
+	events    []Event
+	clock     *clock.Clock // clock attached to the game, nil if moves are not timed
+}
+
+// New returns a game starting from the initial position
+func New() *Game {
+	var b board.Board
+	b.SetInitialBoard()
+	return NewFromBoard(&b)
+}
+
+// NewFromFEN returns a game starting from the position described in FEN
+func NewFromFEN(fen string) (*Game, error) {
+	b, err := board.ParseFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromBoard(b), nil
+}
+
+// NewChess960 returns a game starting from the numbered Chess960 position, 518 being the classic setup
+func NewChess960(id int) (*Game, error) {
+	var b board.Board
+	if err := b.SetChess960Position(id); err != nil {
+		return nil, err
+	}
+	g := NewFromBoard(&b)
+	g.SetTag("Variant", "Chess960")
+	return g, nil
+}
+
+// NewFromBoard returns a game starting from a copy of the position
+func NewFromBoard(b *board.Board) *Game {
+	root := &node{}
+	return &Game{start: *b, board: *b, root: root, cur: root, hashes: []uint64{b.Hash}, drawOffer: board.None}
+}
+
+// Board returns a copy of the position at the cursor
+func (g *Game) Board() board.Board {
+	return g.board
+}
+
+// StartFEN returns the starting position of the game in FEN
+func (g *Game) StartFEN() string {
+	return g.start.ToFEN()
+}
+
+// Ply returns the number of half moves played to reach the cursor
+func (g *Game) Ply() int {
+	return len(g.path)
+}
+
+// Moves returns the moves played to reach the cursor
+func (g *Game) Moves() []board.Move {
+	moves := make([]board.Move, len(g.path))
+	for i, n := range g.path {
+		moves[i] = n.move
+	}
+	return moves
+}
+
+// SAN returns the moves played to reach the cursor in Standard Algebraic Notation
+func (g *Game) SAN() []string {
+	sans := make([]string, len(g.path))
+	for i, n := range g.path {
+		sans[i] = n.san
+	}
+	return sans
+}
+
+// LegalMoves returns the moves available in the current position, none once the game is over
+func (g *Game) LegalMoves() []board.Move {
+	if g.Outcome().Result != InProgress {
+		return nil
+	}
+	return legalMoves(&g.board)
+}
+
+func legalMoves(b *board.Board) []board.Move {
+	var ml board.MoveList
+	b.GenerateLegalMoves(&ml)
+	return append([]board.Move(nil), ml.Moves[:ml.Count]...)
+}
+
+// Play plays a legal move at the cursor. The move only needs its source, target and promotion piece,
+// the type is taken from the matching legal move. A move already in the history is followed,
+// a new move played before the end of the line starts a variation.
+// With a clock attached the move presses it, recording the time left to the player,
+// and a fallen flag ends the game instead.
+func (g *Game) Play(m board.Move) error {
+	if g.over() {
+		return ErrGameOver
+	}
+	legal, err := g.board.ParseUCIMove(m.UCI())
+	if err != nil {
+		return err
+	}
+	parent := g.cur
+	child := parent.child(legal)
+	added := child == nil
+	if added {
+		child = &node{parent: parent, move: legal, san: g.board.MoveToSAN(legal)}
+	}
+	if err := g.pressClock(child); err != nil {
+		return err
+	}
+	if err := g.enter(child); err != nil {
+		return err
+	}
+	// Only a move actually played joins the tree
+	if added {
+		parent.children = append(parent.children, child)
+	}
+	if g.clock != nil && g.Outcome().Result != InProgress {
+		g.clock.Stop()
+	}
+
+	// Moving instead of answering a draw offer declines it
+	if g.drawOffer == g.board.SideToMove {
+		g.drawOffer = board.None
+		g.events = append(g.events, Event{Ply: g.Ply(), Color: g.board.SideToMove ^ 1, Kind: DrawDeclined})
+	}
+	return nil
+}
+
+// PlaySAN plays a move written in Standard Algebraic Notation
+func (g *Game) PlaySAN(san string) error {
+	if g.over() {
+		return ErrGameOver
+	}
+	m, err := g.board.ParseSAN(san)
+	if err != nil {
+		return err
+	}
+	return g.Play(m)
+}
+
+// PlayUCI plays a move written in UCI long algebraic notation
+func (g *Game) PlayUCI(uci string) error {
+	if g.over() {
+		return ErrGameOver
+	}
+	m, err := g.board.ParseUCIMove(uci)
+	if err != nil {
+		return err
+	}
+	return g.Play(m)
+}
+
+// Repetitions returns how many times the current position has occurred in the game, counting itself.
+// Positions are the same when they have the same pieces, side to move, castling rights and en passant capture.
+func (g *Game) Repetitions() int {
+	current := g.hashes[len(g.hashes)-1]
+	count := 1
+	// Captures and pawn moves cannot be undone, so only look back as far as the halfmove clock
+	// goes, at positions with the same side to move
+	oldest := max(0, len(g.hashes)-1-g.board.HalfMoveClock)
+	for i := len(g.hashes) - 3; i >= oldest; i -= 2 {
+		if g.hashes[i] == current {
+			count++
+		}
+	}
+	return count
+}
+
+// Outcome reports whether the game is over at the cursor, with its result and the reason.
+// Resignations, agreements and claims only end the game in the position where they happened.
+func (g *Game) Outcome() Outcome {
+	if g.cur.end.Result != "" {
+		return g.cur.end
+	}
+	b := &g.board
+	var ml board.MoveList
+	b.GenerateLegalMoves(&ml)
+	if ml.Count == 0 {
+		if b.InCheck() {
+			winner := WhiteWins
+			if b.SideToMove == board.White {
+				winner = BlackWins
+			}
+			return Outcome{Result: winner, Reason: Checkmate}
+		}
+		return Outcome{Result: Draw, Reason: Stalemate}
+	}
+
+	// Automatic draws, threefold repetition and the fifty-move rule need a claim
+	switch {
+	case g.Repetitions() >= 5:
+		return Outcome{Result: Draw, Reason: FivefoldRepetition}
+	case b.HalfMoveClock >= seventyFiveMovePlies:
+		return Outcome{Result: Draw, Reason: SeventyFiveMoveRule}
+	case b.IsInsufficientMaterial():
+		return Outcome{Result: Draw, Reason: InsufficientMaterial}
+	}
+	return Outcome{Result: InProgress, Reason: NoReason}
+}
+
+// PGN returns the game with its variations as a PGN game ready to be written.
+// The result tag is set from the outcome at the end of the main line.
+func (g *Game) PGN() *pgn.Game {
+	cursor, end := g.cur, g.root
+	for len(end.children) > 0 {
+		end = end.children[0]
+	}
+	g.goTo(end)
+	result := g.Outcome().Result
+	g.goTo(cursor)
+
+	pg := &pgn.Game{
+		Tags:    append(pgn.Tags(nil), g.Tags...),
+		Start:   g.start,
+		Comment: g.root.comment,
+		Moves:   exportContinuation(g.root),
+		Result:  string(result),
+	}
+	pg.SetTag("Result", pg.Result)
+	return pg
+}
+
+// exportContinuation converts the moves after n, following the main continuation
+func exportContinuation(n *node) []pgn.Move {
+	var moves []pgn.Move
+	for len(n.children) > 0 {
+		main := n.children[0]
+		m := exportMove(main)
+		for _, v := range n.children[1:] {
+			m.Variations = append(m.Variations, append([]pgn.Move{exportMove(v)}, exportContinuation(v)...))
+		}
+		moves = append(moves, m)
+		n = main
+	}
+	return moves
+}
+
+// exportMove converts the move reaching n with its annotations
+func exportMove(n *node) pgn.Move {
+	return pgn.Move{
+		Move:     n.move,
+		SAN:      n.san,
+		NAGs:     append([]int(nil), n.nags...),
+		Before:   n.before,
+		Comment:  n.comment,
+		Clock:    n.clock,
+		HasClock: n.hasClock,
+		Eval:     n.eval,
+		Mate:     n.mate,
+		HasEval:  n.hasEval,
+	}
+}
+
+// annotate keeps the annotations of an imported move on the node playing it
+func (n *node) annotate(m pgn.Move) {
+	n.before, n.comment = m.Before, m.Comment
+	n.nags = append([]int(nil), m.NAGs...)
+	if m.HasClock {
+		n.clock, n.hasClock = m.Clock, true
+	}
+	if m.HasEval {
+		n.eval, n.mate, n.hasEval = m.Eval, m.Mate, true
+	}
+}
+
+// FromPGN replays a PGN game with its variations and annotations, leaving the cursor at the end
+// of the main line. A decided result with no outcome on the board, like a resignation, is kept.
+func FromPGN(pg *pgn.Game) (*Game, error) {
+	start := pg.StartPosition()
+	g := NewFromBoard(&start)
+	g.Tags = append(g.Tags, pg.Tags...)
+	g.root.comment = pg.Comment
+	if err := g.importLine(pg.Moves); err != nil {
+		return nil, err
+	}
+	switch r := Result(pg.Result); r {
+	case WhiteWins, BlackWins, Draw:
+		if g.Outcome().Result == InProgress {
+			g.cur.end = Outcome{Result: r, Reason: RecordedResult}
+		}
+	}
+	return g, nil
+}
+
+// importLine plays the moves from the cursor, adding their variations on the way
+func (g *Game) importLine(moves []pgn.Move) error {
+	for _, m := range moves {
+		if err := g.Play(m.Move); err != nil {
+			return fmt.Errorf("ply %d: %v", g.Ply()+1, err)
+		}
+		g.cur.annotate(m)
+		if len(m.Variations) == 0 {
+			continue
+		}
+		// Variations replace the move, so they start from the position before it
+		played := g.cur
+		for _, v := range m.Variations {
+			g.goTo(played.parent)
+			if err := g.importLine(v); err != nil {
+				return err
+			}
+		}
+		g.goTo(played)
+	}
+	return nil
+}
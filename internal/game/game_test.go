@@ -0,0 +1,176 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+	"github.com/deadpyxel/cheesy/internal/pgn"
+)
+
+// playSAN plays the moves, failing the test on the first error.
+func playSAN(t *testing.T, g *Game, moves ...string) {
+	t.Helper()
+	for _, san := range moves {
+		if err := g.PlaySAN(san); err != nil {
+			t.Fatalf("Expected no error playing %s, got %v instead", san, err)
+		}
+	}
+}
+
+func TestPlay(t *testing.T) {
+	g := New()
+	playSAN(t, g, "e4", "e5", "Nf3")
+	if err := g.PlayUCI("b8c6"); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	// Moves only need their squares, the type comes from the legal move
+	if err := g.Play(board.Move{From: 5, To: 33}); err != nil { // Bb5
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+
+	if g.Ply() != 5 {
+		t.Errorf("expected 5 plies, got %d instead", g.Ply())
+	}
+	if want := []string{"e4", "e5", "Nf3", "Nc6", "Bb5"}; !reflect.DeepEqual(g.SAN(), want) {
+		t.Errorf("expected SAN history %v, got %v instead", want, g.SAN())
+	}
+	if b := g.Board(); b.ToFEN() != "r1bqkbnr/pppp1ppp/2n5/1B2p3/4P3/5N2/PPPP1PPP/RNBQK2R b KQkq - 3 3" {
+		t.Errorf("unexpected position %s", b.ToFEN())
+	}
+	if g.StartFEN() != "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1" {
+		t.Errorf("unexpected start position %s", g.StartFEN())
+	}
+
+	// Illegal moves leave the game untouched
+	for _, err := range []error{g.PlaySAN("Ke6"), g.PlayUCI("e1g1"), g.Play(board.Move{From: 60, To: 62})} {
+		if err == nil {
+			t.Errorf("expected error for illegal move, got nil instead")
+		}
+	}
+	if g.Ply() != 5 {
+		t.Errorf("expected 5 plies after illegal moves, got %d instead", g.Ply())
+	}
+}
+
+func TestPlayAfterGameOver(t *testing.T) {
+	g := New()
+	playSAN(t, g, "f3", "e5", "g4", "Qh4#")
+	if err := g.PlaySAN("a3"); !errors.Is(err, ErrGameOver) {
+		t.Errorf("expected ErrGameOver, got %v instead", err)
+	}
+	if moves := g.LegalMoves(); len(moves) != 0 {
+		t.Errorf("expected no legal moves, got %v instead", moves)
+	}
+}
+
+func TestNewFromFEN(t *testing.T) {
+	g, err := NewFromFEN("4k3/8/8/8/8/8/4P3/4K3 b - - 0 40")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if len(g.LegalMoves()) != 5 {
+		t.Errorf("expected 5 legal moves, got %d instead", len(g.LegalMoves()))
+	}
+	if _, err := NewFromFEN("not a fen"); err == nil {
+		t.Errorf("expected error for invalid FEN, got nil instead")
+	}
+}
+
+func TestNewChess960(t *testing.T) {
+	g, err := NewChess960(959)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if want := "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB w KQkq - 0 1"; g.StartFEN() != want {
+		t.Errorf("expected start %s, got %s instead", want, g.StartFEN())
+	}
+	if v, ok := g.Tag("Variant"); !ok || v != "Chess960" {
+		t.Errorf("expected Variant tag Chess960, got %q instead", v)
+	}
+	if b := g.Board(); !b.Chess960 {
+		t.Errorf("expected the board to use Chess960 castling")
+	}
+
+	// The classic setup keeps Chess960 castling through its FEN
+	g, err = NewChess960(board.Chess960Standard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if want := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1"; g.StartFEN() != want {
+		t.Errorf("expected start %s, got %s instead", want, g.StartFEN())
+	}
+	if back, err := NewFromFEN(g.StartFEN()); err != nil || !back.board.Chess960 {
+		t.Errorf("expected the start FEN to read back as Chess960, got %v instead", err)
+	}
+	if _, err := NewChess960(960); err == nil {
+		t.Errorf("expected error for an invalid position number, got nil instead")
+	}
+}
+
+func TestTags(t *testing.T) {
+	g := New()
+	g.SetTag("White", "A")
+	g.SetTag("White", "B")
+	if v, ok := g.Tag("White"); !ok || v != "B" || len(g.Tags) != 1 {
+		t.Errorf("expected a single White tag B, got %v instead", g.Tags)
+	}
+	if _, ok := g.Tag("Black"); ok {
+		t.Errorf("expected no Black tag")
+	}
+}
+
+func TestPGN(t *testing.T) {
+	g := New()
+	g.SetTag("Event", "Test")
+	playSAN(t, g, "f3", "e5", "g4", "Qh4#")
+
+	var sb strings.Builder
+	if err := pgn.NewWriter(&sb).Write(g.PGN()); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if !strings.Contains(sb.String(), "[Result \"0-1\"]") || !strings.Contains(sb.String(), "1. f3 e5 2. g4 Qh4# 0-1") {
+		t.Errorf("unexpected PGN:\n%s", sb.String())
+	}
+
+	pg, err := pgn.NewReader(strings.NewReader(sb.String())).Read()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	back, err := FromPGN(pg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if !reflect.DeepEqual(back.SAN(), g.SAN()) || back.Outcome() != g.Outcome() {
+		t.Errorf("expected the same game back, got %v (%v)", back.SAN(), back.Outcome())
+	}
+	if v, _ := back.Tag("Event"); v != "Test" {
+		t.Errorf("expected Event tag Test, got %q instead", v)
+	}
+}
+
+func TestPGNKeepsResultAndAnnotations(t *testing.T) {
+	// Resigned game, the result is not on the board
+	movetext := "{Opening} 1. e4 e5 {good} 2. Qh5 $1 {[%clk 0:01:00]} (2. Nf3 {ok}) 2... Nc6 1-0"
+	pg, err := pgn.NewReader(strings.NewReader("[Event \"Resigned\"]\n\n" + movetext + "\n")).Read()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	g, err := FromPGN(pg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if want := (Outcome{WhiteWins, RecordedResult}); g.Outcome() != want {
+		t.Errorf("expected %v, got %v instead", want, g.Outcome())
+	}
+
+	var sb strings.Builder
+	if err := pgn.NewWriter(&sb).Write(g.PGN()); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if !strings.Contains(sb.String(), "[Result \"1-0\"]") || !strings.Contains(sb.String(), movetext) {
+		t.Errorf("expected the result and annotations to be kept, got:\n%s", sb.String())
+	}
+}
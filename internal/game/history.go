@@ -0,0 +1,190 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// node is a position in the game tree, reached by playing move from its parent
+type node struct {
+	parent   *node
+	move     board.Move
+	san      string
+	children []*node // continuations, the first one is the main line and the others its variations
+	end      Outcome // outcome decided off the board in this position, like a resignation, zero if none
+
+	// Annotations of the move, kept when importing and exporting PGN
+	before   string        // comment before the move, at the start of a variation
+	comment  string        // comment after the move, or before the first move on the root
+	nags     []int         // numeric annotation glyphs
+	clock    time.Duration // time left to the player after the move, when hasClock is set
+	hasClock bool
+	eval     float64 // evaluation in pawns, or mate distance when mate is not zero, when hasEval is set
+	mate     int
+	hasEval  bool
+}
+
+// child returns the continuation playing the move, nil if there is none
+func (n *node) child(m board.Move) *node {
+	for _, c := range n.children {
+		if c.move.UCI() == m.UCI() {
+			return c
+		}
+	}
+	return nil
+}
+
+// childIndex returns the position of the continuation playing the move, -1 if there is none
+func (n *node) childIndex(m board.Move) int {
+	for i, c := range n.children {
+		if c.move.UCI() == m.UCI() {
+			return i
+		}
+	}
+	return -1
+}
+
+// enter plays the move of a child of the cursor and moves the cursor to it
+func (g *Game) enter(n *node) error {
+	undo, err := g.board.MakeMove(n.move)
+	if err != nil {
+		return err
+	}
+	g.cur = n
+	g.path = append(g.path, n)
+	g.undos = append(g.undos, undo)
+	g.hashes = append(g.hashes, g.board.Hash)
+	return nil
+}
+
+// Back takes back the last move, it returns false at the start of the game
+func (g *Game) Back() bool {
+	ok := g.back()
+	g.syncClock()
+	return ok
+}
+
+func (g *Game) back() bool {
+	if g.cur.parent == nil {
+		return false
+	}
+	last := len(g.path) - 1
+	g.board.UnmakeMove(g.cur.move, g.undos[last])
+	g.path = g.path[:last]
+	g.undos = g.undos[:last]
+	g.hashes = g.hashes[:last+1]
+	g.cur = g.cur.parent
+	return true
+}
+
+// Forward replays the main continuation of the current position, it returns false at the end of the line
+func (g *Game) Forward() bool {
+	ok := g.forward()
+	g.syncClock()
+	return ok
+}
+
+func (g *Game) forward() bool {
+	if len(g.cur.children) == 0 {
+		return false
+	}
+	// Moves in the tree were legal when added, replaying them cannot fail
+	return g.enter(g.cur.children[0]) == nil
+}
+
+// Start moves the cursor to the starting position
+func (g *Game) Start() {
+	for g.back() {
+	}
+	g.syncClock()
+}
+
+// End moves the cursor to the end of the current line, following main continuations
+func (g *Game) End() {
+	for g.forward() {
+	}
+	g.syncClock()
+}
+
+// GoTo moves the cursor to the given ply of the current line, going back or following main continuations
+func (g *Game) GoTo(ply int) error {
+	if ply < 0 {
+		return fmt.Errorf("invalid ply %d", ply)
+	}
+	if ply <= g.Ply() {
+		for g.Ply() > ply {
+			g.back()
+		}
+		g.syncClock()
+		return nil
+	}
+
+	// Check the line is long enough before moving
+	n, available := g.cur, 0
+	for g.Ply()+available < ply && len(n.children) > 0 {
+		n = n.children[0]
+		available++
+	}
+	if g.Ply()+available < ply {
+		return fmt.Errorf("ply %d is past the end of the line at ply %d", ply, g.Ply()+available)
+	}
+	for g.Ply() < ply {
+		g.forward()
+	}
+	g.syncClock()
+	return nil
+}
+
+// goTo moves the cursor to any node of the tree, leaving the clock alone
+func (g *Game) goTo(n *node) {
+	var target []*node
+	onPath := map[*node]bool{}
+	for p := n; p != nil; p = p.parent {
+		target = append(target, p)
+		onPath[p] = true
+	}
+	for !onPath[g.cur] {
+		g.back()
+	}
+	// target goes from n up to the root, replay it downwards from the cursor
+	for i := len(target) - 1; i >= 0; i-- {
+		if target[i].parent == g.cur {
+			g.enter(target[i])
+		}
+	}
+}
+
+// Variations returns the moves continuing from the current position, the main continuation first
+func (g *Game) Variations() []board.Move {
+	moves := make([]board.Move, len(g.cur.children))
+	for i, c := range g.cur.children {
+		moves[i] = c.move
+	}
+	return moves
+}
+
+// PromoteVariation makes the continuation playing the move the main one from the current position
+func (g *Game) PromoteVariation(m board.Move) error {
+	i := g.cur.childIndex(m)
+	if i < 0 {
+		return fmt.Errorf("no variation %s from the current position", m.UCI())
+	}
+	children := g.cur.children
+	promoted := children[i]
+	copy(children[1:i+1], children[:i])
+	children[0] = promoted
+	return nil
+}
+
+// DeleteVariation removes the continuation playing the move from the current position, with all its moves.
+// Deleting the main continuation makes the first variation the new main one.
+func (g *Game) DeleteVariation(m board.Move) error {
+	i := g.cur.childIndex(m)
+	if i < 0 {
+		return fmt.Errorf("no variation %s from the current position", m.UCI())
+	}
+	g.cur.children = append(g.cur.children[:i], g.cur.children[i+1:]...)
+	return nil
+}
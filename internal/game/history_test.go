@@ -0,0 +1,206 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+	"github.com/deadpyxel/cheesy/internal/pgn"
+)
+
+// uciMoves writes moves in UCI notation for easy comparison.
+func uciMoves(moves []board.Move) []string {
+	s := make([]string, len(moves))
+	for i, m := range moves {
+		s[i] = m.UCI()
+	}
+	return s
+}
+
+func TestNavigation(t *testing.T) {
+	g := New()
+	playSAN(t, g, "e4", "e5", "Nf3", "Nc6")
+	positions := []string{g.StartFEN()}
+	for ply := 1; ply <= 4; ply++ {
+		if err := g.GoTo(ply); err != nil {
+			t.Fatalf("Expected no error, got %v instead", err)
+		}
+		b := g.Board()
+		positions = append(positions, b.ToFEN())
+	}
+	end := g.Board()
+
+	if !g.Back() || g.Ply() != 3 {
+		t.Fatalf("expected to go back to ply 3, at ply %d instead", g.Ply())
+	}
+	if b := g.Board(); b.ToFEN() != positions[3] {
+		t.Errorf("expected %s, got %s instead", positions[3], b.ToFEN())
+	}
+	g.Start()
+	if b := g.Board(); g.Ply() != 0 || b != g.start || g.Back() {
+		t.Errorf("expected the starting position, got %s at ply %d instead", b.ToFEN(), g.Ply())
+	}
+	if !g.Forward() || g.Ply() != 1 {
+		t.Errorf("expected to go forward to ply 1, at ply %d instead", g.Ply())
+	}
+	g.End()
+	if b := g.Board(); b != end || g.Forward() {
+		t.Errorf("expected the final position with all its state, got %s instead", b.ToFEN())
+	}
+
+	if err := g.GoTo(2); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if want := []string{"e4", "e5"}; !reflect.DeepEqual(g.SAN(), want) {
+		t.Errorf("expected history %v, got %v instead", want, g.SAN())
+	}
+	for _, ply := range []int{-1, 5} {
+		if err := g.GoTo(ply); err == nil {
+			t.Errorf("GoTo(%d) expected error, got nil instead", ply)
+		}
+	}
+	if g.Ply() != 2 {
+		t.Errorf("expected failed GoTo calls to leave the cursor at ply 2, got %d instead", g.Ply())
+	}
+}
+
+func TestBranching(t *testing.T) {
+	g := New()
+	playSAN(t, g, "e4", "e5", "Nf3", "Nc6")
+	if err := g.GoTo(2); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+
+	// Playing the existing move follows the history
+	playSAN(t, g, "Nf3")
+	if vars := g.Variations(); len(vars) != 1 || vars[0].UCI() != "b8c6" {
+		t.Errorf("expected the old continuation to be kept, got %v instead", uciMoves(vars))
+	}
+
+	// A different move starts a variation, keeping the main line
+	g.Back()
+	playSAN(t, g, "Bc4", "Nf6")
+	if want := []string{"e4", "e5", "Bc4", "Nf6"}; !reflect.DeepEqual(g.SAN(), want) {
+		t.Errorf("expected history %v, got %v instead", want, g.SAN())
+	}
+	g.GoTo(2)
+	if want := []string{"g1f3", "f1c4"}; !reflect.DeepEqual(uciMoves(g.Variations()), want) {
+		t.Errorf("expected continuations %v, got %v instead", want, uciMoves(g.Variations()))
+	}
+	g.End()
+	if want := []string{"e4", "e5", "Nf3", "Nc6"}; !reflect.DeepEqual(g.SAN(), want) {
+		t.Errorf("expected the main line %v, got %v instead", want, g.SAN())
+	}
+
+	// Promote the variation to main line, then delete the old one
+	g.GoTo(2)
+	bc4, _ := g.board.ParseSAN("Bc4")
+	if err := g.PromoteVariation(bc4); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if want := []string{"f1c4", "g1f3"}; !reflect.DeepEqual(uciMoves(g.Variations()), want) {
+		t.Errorf("expected continuations %v, got %v instead", want, uciMoves(g.Variations()))
+	}
+	nf3, _ := g.board.ParseSAN("Nf3")
+	if err := g.DeleteVariation(nf3); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if err := g.DeleteVariation(nf3); err == nil {
+		t.Errorf("expected error deleting a missing variation, got nil instead")
+	}
+	if err := g.PromoteVariation(nf3); err == nil {
+		t.Errorf("expected error promoting a missing variation, got nil instead")
+	}
+	g.Start()
+	g.End()
+	if want := []string{"e4", "e5", "Bc4", "Nf6"}; !reflect.DeepEqual(g.SAN(), want) {
+		t.Errorf("expected the new main line %v, got %v instead", want, g.SAN())
+	}
+}
+
+func TestRepetitionAfterNavigation(t *testing.T) {
+	g := New()
+	playSAN(t, g, "Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1", "Ng8")
+	if !g.CanClaimDraw() {
+		t.Fatalf("expected a threefold repetition")
+	}
+	g.Back()
+	g.Back()
+	if g.CanClaimDraw() {
+		t.Errorf("expected no repetition after going back")
+	}
+	g.End()
+	if !g.CanClaimDraw() {
+		t.Errorf("expected the repetition again at the end")
+	}
+}
+
+func TestResultStaysOnItsPosition(t *testing.T) {
+	g := New()
+	playSAN(t, g, "e4", "e5", "Qh5")
+	if err := g.Resign(board.Black); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	resigned := Outcome{WhiteWins, Resignation}
+
+	// Earlier positions are still in progress and can branch into a variation
+	g.Back()
+	if got := g.Outcome(); got.Result != InProgress {
+		t.Errorf("expected the game in progress before the resignation, got %v instead", got)
+	}
+	playSAN(t, g, "Nf3", "Nc6")
+	if got := g.Outcome(); got.Result != InProgress {
+		t.Errorf("expected the variation in progress, got %v instead", got)
+	}
+
+	// The main line still ends with the resignation
+	g.GoTo(2)
+	g.End()
+	if got := g.Outcome(); got != resigned {
+		t.Errorf("expected %v at the end of the main line, got %v instead", resigned, got)
+	}
+	if g.PGN().Result != string(WhiteWins) {
+		t.Errorf("expected the PGN result %s, got %s instead", WhiteWins, g.PGN().Result)
+	}
+}
+
+func TestPGNVariations(t *testing.T) {
+	input := `[Event "Tree"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "?"]
+[Black "?"]
+[Result "*"]
+
+1. e4 e5 (1... c5 2. Nf3 (2. c3) 2... d6) (1... e6) 2. Nf3 Nc6 *
+
+`
+	pg, err := pgn.NewReader(strings.NewReader(input)).Read()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	g, err := FromPGN(pg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if want := []string{"e4", "e5", "Nf3", "Nc6"}; !reflect.DeepEqual(g.SAN(), want) {
+		t.Errorf("expected cursor at the end of the main line %v, got %v instead", want, g.SAN())
+	}
+	g.GoTo(1)
+	if want := []string{"e7e5", "c7c5", "e7e6"}; !reflect.DeepEqual(uciMoves(g.Variations()), want) {
+		t.Errorf("expected continuations %v, got %v instead", want, uciMoves(g.Variations()))
+	}
+
+	var sb strings.Builder
+	if err := pgn.NewWriter(&sb).Write(g.PGN()); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	if sb.String() != input {
+		t.Errorf("expected:\n%s\ngot:\n%s", input, sb.String())
+	}
+	if g.Ply() != 1 {
+		t.Errorf("expected PGN to leave the cursor at ply 1, got %d instead", g.Ply())
+	}
+}
@@ -0,0 +1,77 @@
+package game
+
+// Result of a game, written like PGN game termination markers
+type Result string
+
+const (
+	InProgress Result = "*"
+	WhiteWins  Result = "1-0"
+	BlackWins  Result = "0-1"
+	Draw       Result = "1/2-1/2"
+)
+
+// Reason explains how a game ended
+type Reason uint8
+
+const (
+	NoReason             Reason = iota // game still in progress
+	Checkmate                          // side to move is checkmated
+	Stalemate                          // side to move has no legal move and is not in check
+	FiftyMoveRule                      // 50 moves by each side without captures or pawn moves
+	SeventyFiveMoveRule                // 75 moves by each side without captures or pawn moves
+	ThreefoldRepetition                // same position three times
+	FivefoldRepetition                 // same position five times
+	InsufficientMaterial               // no side can checkmate
+	Resignation                        // a player resigned
+	DrawAgreement                      // a draw offer was accepted
+	Timeout                            // a player ran out of time
+	TimeoutDraw                        // a player ran out of time but the opponent cannot checkmate
+	RecordedResult                     // result of an imported game, decided off the board
+)
+
+func (r Reason) String() string {
+	switch r {
+	case NoReason:
+		return "no reason"
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case FiftyMoveRule:
+		return "fifty-move rule"
+	case SeventyFiveMoveRule:
+		return "seventy-five-move rule"
+	case ThreefoldRepetition:
+		return "threefold repetition"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	case InsufficientMaterial:
+		return "insufficient material"
+	case Resignation:
+		return "resignation"
+	case DrawAgreement:
+		return "agreement"
+	case Timeout:
+		return "timeout"
+	case TimeoutDraw:
+		return "timeout vs insufficient material"
+	case RecordedResult:
+		return "recorded result"
+	}
+	return "unknown reason"
+}
+
+// Outcome is the result of a game and the reason for it.
+// Threefold repetition and the fifty-move rule only end a game when a player claims the draw,
+// fivefold repetition, the seventy-five-move rule and dead positions end it automatically.
+type Outcome struct {
+	Result Result
+	Reason Reason
+}
+
+func (o Outcome) String() string {
+	if o.Result == InProgress {
+		return string(o.Result)
+	}
+	return string(o.Result) + " by " + o.Reason.String()
+}
@@ -0,0 +1,51 @@
+package game
+
+import "testing"
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+		want  Outcome
+	}{
+		{"in progress", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", nil, Outcome{InProgress, NoReason}},
+		{"black checkmates", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", []string{"f3", "e5", "g4", "Qh4#"}, Outcome{BlackWins, Checkmate}},
+		{"white checkmates", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", []string{"Ra8#"}, Outcome{WhiteWins, Checkmate}},
+		{"stalemate", "7k/8/6Q1/8/8/8/8/6K1 w - - 0 1", []string{"Qf7"}, Outcome{Draw, Stalemate}},
+		{"mating material left after capture", "4k3/8/8/8/8/8/3r4/3QK3 w - - 0 1", []string{"Qxd2"}, Outcome{InProgress, NoReason}},
+		{"bare kings", "4k3/8/8/8/8/8/3q4/4K3 w - - 0 1", []string{"Kxd2"}, Outcome{Draw, InsufficientMaterial}},
+		{"fifty-move rule needs a claim", "4k3/8/8/8/8/8/4P3/R3K3 w - - 99 80", []string{"Ra2"}, Outcome{InProgress, NoReason}},
+		{"seventy-five-move rule after a move", "4k3/8/8/8/8/8/4P3/R3K3 w - - 149 80", []string{"Ra2"}, Outcome{Draw, SeventyFiveMoveRule}},
+		{"seventy-five-move rule", "4k3/8/8/8/8/8/4P3/R3K3 w - - 150 80", nil, Outcome{Draw, SeventyFiveMoveRule}},
+		{"pawn move resets the count", "4k3/8/8/8/8/8/4P3/R3K3 w - - 99 80", []string{"e4"}, Outcome{InProgress, NoReason}},
+		{"checkmate wins over the fifty-move rule", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 99 80", []string{"Ra8#"}, Outcome{WhiteWins, Checkmate}},
+		{
+			"threefold repetition needs a claim", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			[]string{"Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1", "Ng8"},
+			Outcome{InProgress, NoReason},
+		},
+		{
+			"fivefold repetition", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			[]string{"Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1", "Ng8"},
+			Outcome{Draw, FivefoldRepetition},
+		},
+		{
+			"lost castling rights make a different position", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			[]string{"Ke2", "Ke7", "Ke1", "Ke8", "Ke2", "Ke7", "Ke1", "Ke8", "Ke2", "Ke7", "Ke1", "Ke8", "Ke2", "Ke7", "Ke1", "Ke8"},
+			Outcome{InProgress, NoReason},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewFromFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			playSAN(t, g, tt.moves...)
+			if got := g.Outcome(); got != tt.want {
+				t.Errorf("expected %v, got %v instead", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+	"github.com/deadpyxel/cheesy/internal/clock"
+)
+
+// AttachClock times the moves played from now on with the clock, starting it for the side to move.
+// A nil clock detaches the current one, leaving it stopped. Moving the cursor gives the turn
+// to the side to move in the new position, stopping the clock where the game is over.
+func (g *Game) AttachClock(c *clock.Clock) {
+	if g.clock != nil {
+		g.clock.Stop()
+	}
+	g.clock = c
+	if c != nil && g.Outcome().Result == InProgress {
+		c.Start(g.board.SideToMove)
+	}
+}
+
+// Clock returns the clock attached to the game, nil if there is none
+func (g *Game) Clock() *clock.Clock {
+	return g.clock
+}
+
+// syncClock runs the clock for the side to move after the cursor moved, so a move played
+// after going back is timed and the thinking time is charged to the right player.
+// The clock stops in a position where the game is over.
+func (g *Game) syncClock() {
+	if g.clock == nil {
+		return
+	}
+	if g.Outcome().Result != InProgress {
+		g.clock.Stop()
+		return
+	}
+	if running, ok := g.clock.Running(); !ok || running != g.board.SideToMove {
+		g.clock.Start(g.board.SideToMove)
+	}
+}
+
+// pressClock ends the turn of the side to move before playing the move reaching n,
+// recording on n the time the player has left. A fallen flag ends the game.
+func (g *Game) pressClock(n *node) error {
+	if g.clock == nil {
+		return nil
+	}
+	if running, ok := g.clock.Running(); !ok || running != g.board.SideToMove {
+		return nil
+	}
+	mover := g.board.SideToMove
+	if err := g.clock.Press(); err != nil {
+		if errors.Is(err, clock.ErrFlagFall) {
+			g.CheckFlag()
+			return ErrGameOver
+		}
+		return err
+	}
+	n.clock, n.hasClock = g.clock.Remaining(mover), true
+	return nil
+}
+
+// CheckFlag ends the game at the cursor when a player ran out of time, reporting whether it did.
+// The opponent wins, unless they could not checkmate with any series of legal moves, which makes
+// it a draw. Moves check the flag before being played, a game waiting for a move needs to call it.
+func (g *Game) CheckFlag() bool {
+	if g.clock == nil || g.Outcome().Result != InProgress {
+		return false
+	}
+	flagged, ok := g.clock.Flagged()
+	if !ok {
+		return false
+	}
+	o := Outcome{Result: WhiteWins, Reason: Timeout}
+	if flagged == board.White {
+		o.Result = BlackWins
+	}
+	if !g.board.HasMatingMaterial(flagged ^ 1) {
+		o = Outcome{Result: Draw, Reason: TimeoutDraw}
+	}
+	g.finish(flagged, FlagFell, o)
+	return true
+}
+
+// over checks the flag and reports whether the game is over at the cursor
+func (g *Game) over() bool {
+	g.CheckFlag()
+	return g.Outcome().Result != InProgress
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+	"github.com/deadpyxel/cheesy/internal/clock"
+)
+
+// attachManualClock attaches a clock for the control driven by a manual source
+func attachManualClock(t *testing.T, g *Game, control clock.Control) *clock.Manual {
+	t.Helper()
+	src := clock.NewManual(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	c, err := clock.New(control, src)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	g.AttachClock(c)
+	return src
+}
+
+func TestFlagFall(t *testing.T) {
+	// The side to move runs out of time
+	tests := []struct {
+		name string
+		fen  string
+		want Outcome
+	}{
+		{"black flags against a rook", "4k3/8/8/8/8/8/4P3/R3K3 b - - 0 1", Outcome{WhiteWins, Timeout}},
+		{"white flags against a bare king", "4k3/8/8/8/8/8/4P3/R3K3 w - - 0 1", Outcome{Draw, TimeoutDraw}},
+		{"white flags against a knight with a queen", "4k1n1/8/8/8/8/8/8/3QK3 w - - 0 1", Outcome{Draw, TimeoutDraw}},
+		{"white flags against a knight with blockers", "4k1n1/8/8/8/8/8/4P3/R3K3 w - - 0 1", Outcome{BlackWins, Timeout}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewFromFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("invalid test FEN %s: %v", tt.fen, err)
+			}
+			src := attachManualClock(t, g, clock.SuddenDeath(time.Minute))
+			src.Advance(time.Minute)
+			if got := g.Outcome(); got.Result != InProgress {
+				t.Errorf("expected Outcome to leave the game in progress until the flag is checked, got %v instead", got)
+			}
+			if !g.CheckFlag() {
+				t.Fatalf("expected the flag fall to end the game")
+			}
+			if g.CheckFlag() {
+				t.Errorf("expected a single flag fall")
+			}
+			if got := g.Outcome(); got != tt.want {
+				t.Errorf("expected %v, got %v instead", tt.want, got)
+			}
+			if g.LegalMoves() != nil {
+				t.Errorf("expected no legal moves after the flag fall")
+			}
+			events := g.Events()
+			if len(events) != 1 || events[0].Kind != FlagFell {
+				t.Errorf("expected a single flag fall event, got %v instead", events)
+			}
+		})
+	}
+}
+
+func TestClockMoves(t *testing.T) {
+	g := New()
+	src := attachManualClock(t, g, clock.Fischer(time.Minute, 2*time.Second))
+	for _, san := range []string{"f3", "e5", "g4"} {
+		src.Advance(5 * time.Second)
+		playSAN(t, g, san)
+	}
+	src.Advance(time.Second)
+	playSAN(t, g, "Qh4#")
+
+	if want := (Outcome{BlackWins, Checkmate}); g.Outcome() != want {
+		t.Fatalf("expected %v, got %v instead", want, g.Outcome())
+	}
+	if _, ok := g.Clock().Running(); ok {
+		t.Errorf("expected checkmate to stop the clock")
+	}
+
+	// The time left after each move is exported as [%clk] and read back from PGN
+	want := []time.Duration{57 * time.Second, 57 * time.Second, 54 * time.Second, 58 * time.Second}
+	pg := g.PGN()
+	for i, m := range pg.Moves {
+		if !m.HasClock || m.Clock != want[i] {
+			t.Errorf("move %d: expected clock %v, got %v (%v) instead", i+1, want[i], m.Clock, m.HasClock)
+		}
+	}
+	imported, err := FromPGN(pg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	for i, m := range imported.PGN().Moves {
+		if m.Clock != want[i] {
+			t.Errorf("imported move %d: expected clock %v, got %v instead", i+1, want[i], m.Clock)
+		}
+	}
+}
+
+func TestClockFlagBeforeMove(t *testing.T) {
+	g := New()
+	src := attachManualClock(t, g, clock.SuddenDeath(time.Minute))
+	playSAN(t, g, "e4")
+	src.Advance(2 * time.Minute)
+	if err := g.PlaySAN("e5"); !errors.Is(err, ErrGameOver) {
+		t.Fatalf("expected ErrGameOver, got %v instead", err)
+	}
+	if want := (Outcome{WhiteWins, Timeout}); g.Outcome() != want {
+		t.Errorf("expected %v, got %v instead", want, g.Outcome())
+	}
+	if g.Ply() != 1 {
+		t.Errorf("expected the late move not to be played, got %d plies instead", g.Ply())
+	}
+	if vars := g.Variations(); len(vars) != 0 {
+		t.Errorf("expected the late move not to join the history, got %v instead", uciMoves(vars))
+	}
+}
+
+func TestClockAfterGoingBack(t *testing.T) {
+	g := New()
+	src := attachManualClock(t, g, clock.SuddenDeath(time.Minute))
+	playSAN(t, g, "e4")
+	g.Back()
+	if running, ok := g.Clock().Running(); !ok || running != board.White {
+		t.Fatalf("expected the clock running for white after going back, got %v (%v) instead", running, ok)
+	}
+	src.Advance(20 * time.Second)
+	playSAN(t, g, "d4")
+	if got := g.Clock().Remaining(board.White); got != 40*time.Second {
+		t.Errorf("expected white to be charged for the move, got %v left instead", got)
+	}
+	if got := g.Clock().Remaining(board.Black); got != time.Minute {
+		t.Errorf("expected black to keep all their time, got %v left instead", got)
+	}
+
+	// Going back from a finished position restarts the clock, coming back stops it
+	playSAN(t, g, "d5")
+	if err := g.Resign(board.White); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	g.Back()
+	if running, ok := g.Clock().Running(); !ok || running != board.Black {
+		t.Errorf("expected the clock running for black before the resignation, got %v (%v) instead", running, ok)
+	}
+	g.Forward()
+	if _, ok := g.Clock().Running(); ok {
+		t.Errorf("expected the clock stopped after the resignation")
+	}
+}
@@ -0,0 +1,97 @@
+// Package pgn reads and writes chess games in Portable Game Notation.
+package pgn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// Game termination markers
+const (
+	WhiteWins  = "1-0"
+	BlackWins  = "0-1"
+	Draw       = "1/2-1/2"
+	Unfinished = "*"
+)
+
+// Tag is a PGN tag pair, like [Event "Casual game"]
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Tags is a list of tag pairs, kept in order
+type Tags []Tag
+
+// Tag returns the value of the named tag, ok is false if there is no such tag
+func (ts Tags) Tag(name string) (value string, ok bool) {
+	for _, t := range ts {
+		if t.Name == name {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetTag sets the value of the named tag, adding it at the end if missing
+func (ts *Tags) SetTag(name, value string) {
+	for i := range *ts {
+		if (*ts)[i].Name == name {
+			(*ts)[i].Value = value
+			return
+		}
+	}
+	*ts = append(*ts, Tag{Name: name, Value: value})
+}
+
+// Game is a game read from PGN, with its main line and any annotations and variations
+type Game struct {
+	Tags                // tag pairs in the order they were read
+	Start   board.Board // position before the first move from the FEN tag, a zero Board means the initial position
+	Comment string      // comment before the first move
+	Moves   []Move      // main line
+	Result  string      // game termination marker
+}
+
+// Move is a move played in a game together with its annotations
+type Move struct {
+	Move       board.Move
+	SAN        string        // move as written in the movetext, without suffix annotations
+	NAGs       []int         // numeric annotation glyphs, "!" and "?" suffixes are converted to $1 to $6
+	Before     string        // comment placed before the move, at the start of a variation
+	Comment    string        // comment after the move, without its [%clk] and [%eval] commands
+	Clock      time.Duration // remaining time from a [%clk] command
+	HasClock   bool
+	Eval       float64 // evaluation in pawns from White's point of view, from an [%eval] command
+	Mate       int     // moves to mate from an [%eval #N] command, negative when Black mates
+	HasEval    bool
+	Variations [][]Move // alternative lines replacing this move, played from the position before it
+}
+
+// StartPosition returns the position before the first move, the initial position when Start was left empty
+func (g *Game) StartPosition() board.Board {
+	if g.Start.OccupiedSquares == 0 {
+		var initial board.Board
+		initial.SetInitialBoard()
+		initial.Chess960 = g.Start.Chess960
+		return initial
+	}
+	return g.Start
+}
+
+// SyntaxError reports malformed PGN or an illegal move, with the position where it was found
+type SyntaxError struct {
+	Line   int // line number, starting at 1
+	Column int // column in characters, starting at 1
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("pgn: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+func syntaxError(t token, format string, args ...any) error {
+	return &SyntaxError{Line: t.line, Column: t.col, Msg: fmt.Sprintf(format, args...)}
+}
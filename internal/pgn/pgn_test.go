@@ -0,0 +1,25 @@
+package pgn
+
+import "testing"
+
+func TestGameTags(t *testing.T) {
+	g := &Game{Tags: []Tag{{"Event", "Test"}, {"Result", "*"}}}
+	if v, ok := g.Tag("Event"); !ok || v != "Test" {
+		t.Errorf("expected Event tag Test, got %q (%v) instead", v, ok)
+	}
+	if _, ok := g.Tag("Site"); ok {
+		t.Errorf("expected no Site tag")
+	}
+
+	g.SetTag("Result", "1-0")
+	g.SetTag("Site", "Home")
+	want := []Tag{{"Event", "Test"}, {"Result", "1-0"}, {"Site", "Home"}}
+	if len(g.Tags) != len(want) {
+		t.Fatalf("expected tags %v, got %v instead", want, g.Tags)
+	}
+	for i := range want {
+		if g.Tags[i] != want[i] {
+			t.Errorf("expected tag %v, got %v instead", want[i], g.Tags[i])
+		}
+	}
+}
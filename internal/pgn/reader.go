@@ -0,0 +1,359 @@
+package pgn
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// Reader reads games one at a time from a PGN stream, so files of any size can be processed.
+type Reader struct {
+	s *scanner
+}
+
+// NewReader returns a Reader reading games from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{s: newScanner(r)}
+}
+
+// Suffix annotations and their equivalent numeric annotation glyphs
+var suffixNAGs = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+// Read returns the next game, replaying its moves to check them. It returns io.EOF when there are no more games.
+// Malformed games return a *SyntaxError, after which Read skips to the next game so reading can continue.
+func (r *Reader) Read() (*Game, error) {
+	g, inTags, err := r.read()
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		if skipErr := r.skipGame(inTags); skipErr != nil {
+			return nil, skipErr
+		}
+	}
+	return g, err
+}
+
+// isChess960 checks the Variant tag names Chess960, under any of its usual spellings
+func isChess960(variant string) bool {
+	switch strings.ToLower(strings.ReplaceAll(variant, " ", "")) {
+	case "chess960", "fischerandom", "fischerrandom", "960":
+		return true
+	}
+	return false
+}
+
+// read reads the next game, reporting if an error happened in its tag section.
+// Comments outside any game, like a header before the first tags, go to the game that follows.
+func (r *Reader) read() (*Game, bool, error) {
+	comment := ""
+	for {
+		t, err := r.s.next()
+		if err != nil {
+			return nil, false, err
+		}
+		if t.kind == tokenEOF {
+			return nil, false, io.EOF
+		}
+		r.s.unread(t)
+
+		g := &Game{}
+		if err := r.readTags(g); err != nil {
+			return nil, true, err
+		}
+		if fen, ok := g.Tag("FEN"); ok {
+			start, err := board.ParseFEN(fen)
+			if err != nil {
+				return nil, false, syntaxError(t, "invalid FEN tag: %v", err)
+			}
+			g.Start = *start
+		} else {
+			g.Start.SetInitialBoard()
+		}
+		if variant, ok := g.Tag("Variant"); ok && isChess960(variant) {
+			g.Start.Chess960 = true
+		}
+
+		b := g.Start
+		moves, result, err := r.readLine(&b, 0, &g.Comment)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(g.Tags) == 0 && len(moves) == 0 && result == "" {
+			comment = joinComment(comment, g.Comment)
+			continue
+		}
+		g.Comment = joinComment(comment, g.Comment)
+		g.Moves = moves
+		g.Result = result
+		if g.Result == "" {
+			// Tolerate a missing termination marker, taking it from the tags
+			g.Result = Unfinished
+			if tag, ok := g.Tag("Result"); ok {
+				g.Result = tag
+			}
+		}
+		return g, false, nil
+	}
+}
+
+// readTags reads the tag pair section of a game
+func (r *Reader) readTags(g *Game) error {
+	for {
+		t, err := r.s.next()
+		if err != nil {
+			return err
+		}
+		if t.kind != tokenLBracket {
+			r.s.unread(t)
+			return nil
+		}
+		name, err := r.s.next()
+		if err != nil {
+			return err
+		}
+		if name.kind != tokenSymbol {
+			return syntaxError(name, "expected tag name")
+		}
+		value, err := r.s.next()
+		if err != nil {
+			return err
+		}
+		if value.kind != tokenString {
+			return syntaxError(value, "expected quoted value for tag %s", name.text)
+		}
+		end, err := r.s.next()
+		if err != nil {
+			return err
+		}
+		if end.kind != tokenRBracket {
+			return syntaxError(end, "expected ] closing tag %s", name.text)
+		}
+		g.Tags = append(g.Tags, Tag{Name: name.text, Value: value.text})
+	}
+}
+
+// readLine reads a line of moves played from b, a variation when depth is above 0.
+// Comments found before the first move go to before. It returns the result when the line ends the game.
+func (r *Reader) readLine(b *board.Board, depth int, before *string) (moves []Move, result string, err error) {
+	var prev board.Board // position before the last move, where its variations start
+	pending := ""        // comments before the first move, waiting for it
+	for {
+		t, err := r.s.next()
+		if err != nil {
+			return nil, "", err
+		}
+		switch t.kind {
+		case tokenEOF:
+			if depth > 0 {
+				return nil, "", syntaxError(t, "unterminated variation")
+			}
+			*before = joinComment(*before, pending)
+			return moves, "", nil
+		case tokenLBracket:
+			// Leave the tag to the next game, also when this one is broken, so skipGame stops at it
+			r.s.unread(t)
+			if depth > 0 {
+				return nil, "", syntaxError(t, "unexpected tag inside a variation")
+			}
+			// Next game started without a termination marker
+			*before = joinComment(*before, pending)
+			return moves, "", nil
+		case tokenRParen:
+			if depth == 0 {
+				return nil, "", syntaxError(t, "unexpected ) outside a variation")
+			}
+			return moves, "", nil
+		case tokenLParen:
+			if len(moves) == 0 {
+				return nil, "", syntaxError(t, "variation before any move")
+			}
+			vb := prev
+			var vcomment string
+			variation, _, err := r.readLine(&vb, depth+1, &vcomment)
+			if err != nil {
+				return nil, "", err
+			}
+			if len(variation) > 0 {
+				variation[0].Before = joinComment(vcomment, variation[0].Before)
+			}
+			last := &moves[len(moves)-1]
+			last.Variations = append(last.Variations, variation)
+		case tokenComment:
+			if len(moves) == 0 {
+				pending = joinComment(pending, strings.TrimSpace(t.text))
+				continue
+			}
+			addComment(&moves[len(moves)-1], t.text)
+		case tokenNAG:
+			if len(moves) == 0 {
+				return nil, "", syntaxError(t, "annotation before any move")
+			}
+			nag, err := strconv.Atoi(t.text)
+			if err != nil || nag > 255 {
+				return nil, "", syntaxError(t, "invalid annotation $%s", t.text)
+			}
+			moves[len(moves)-1].NAGs = append(moves[len(moves)-1].NAGs, nag)
+		case tokenPeriod:
+			// Part of a move number
+		case tokenSymbol:
+			switch {
+			case isResult(t.text):
+				if depth > 0 {
+					return nil, "", syntaxError(t, "game result inside a variation")
+				}
+				if len(moves) == 0 {
+					*before = joinComment(*before, pending)
+				}
+				return moves, t.text, nil
+			case strings.Trim(t.text, "0123456789") == "":
+				// Move number
+			case strings.Trim(t.text, "!?") == "":
+				nag, ok := suffixNAGs[t.text]
+				if !ok || len(moves) == 0 {
+					return nil, "", syntaxError(t, "unexpected annotation %s", t.text)
+				}
+				moves[len(moves)-1].NAGs = append(moves[len(moves)-1].NAGs, nag)
+			default:
+				m, err := r.parseMove(b, t)
+				if err != nil {
+					return nil, "", err
+				}
+				if len(moves) == 0 {
+					*before = joinComment(*before, pending)
+					pending = ""
+				}
+				prev = *b
+				if err := b.PlayMove(m.Move); err != nil {
+					return nil, "", syntaxError(t, "%v", err)
+				}
+				moves = append(moves, m)
+			}
+		default:
+			return nil, "", syntaxError(t, "unexpected token %q in movetext", t.text)
+		}
+	}
+}
+
+// isResult checks the symbol is a game termination marker
+func isResult(s string) bool {
+	return s == WhiteWins || s == BlackWins || s == Draw || s == Unfinished
+}
+
+// parseMove parses a SAN move token with optional suffix annotations, without playing it.
+func (r *Reader) parseMove(b *board.Board, t token) (Move, error) {
+	san := strings.TrimRight(t.text, "!?")
+	m := Move{SAN: san}
+	if suffix := t.text[len(san):]; suffix != "" {
+		nag, ok := suffixNAGs[suffix]
+		if !ok {
+			return Move{}, syntaxError(t, "unknown annotation %s", suffix)
+		}
+		m.NAGs = append(m.NAGs, nag)
+	}
+	mv, err := b.ParseSAN(san)
+	if err != nil {
+		return Move{}, syntaxError(t, "%v", err)
+	}
+	m.Move = mv
+	return m, nil
+}
+
+// skipGame drops tokens up to the tags of the next game, after a malformed one.
+// After an error in the tag section the remaining tags belong to the broken game, so its movetext
+// is skipped too, up to its result or the next tag.
+func (r *Reader) skipGame(inTags bool) error {
+	movetext := !inTags
+	for {
+		t, err := r.s.next()
+		if err != nil {
+			var syntaxErr *SyntaxError
+			if errors.As(err, &syntaxErr) {
+				continue
+			}
+			return err
+		}
+		switch {
+		case t.kind == tokenEOF || t.kind == tokenLBracket && t.col == 1 && movetext:
+			r.s.unread(t)
+			return nil
+		case t.col == 1 && t.kind != tokenLBracket:
+			// A line not starting with a tag starts the movetext
+			movetext = true
+		}
+		if inTags && movetext && t.kind == tokenSymbol && isResult(t.text) {
+			return nil
+		}
+	}
+}
+
+// Commands embedded in comments, like [%clk 1:05:23] or [%eval -0.45]
+var commandRe = regexp.MustCompile(`\[%(\w+)\s+([^\]]*)\]`)
+
+// addComment attaches a comment to the move, extracting the clock and evaluation commands
+func addComment(m *Move, text string) {
+	text = commandRe.ReplaceAllStringFunc(text, func(cmd string) string {
+		parts := commandRe.FindStringSubmatch(cmd)
+		switch parts[1] {
+		case "clk":
+			if d, ok := parseClock(strings.TrimSpace(parts[2])); ok {
+				m.Clock, m.HasClock = d, true
+				return ""
+			}
+		case "eval":
+			if parseEval(m, strings.TrimSpace(parts[2])) {
+				return ""
+			}
+		}
+		return cmd
+	})
+	m.Comment = joinComment(m.Comment, strings.Join(strings.Fields(text), " "))
+}
+
+// parseClock reads a clock time written as h:mm:ss with optional fractions of second
+func parseClock(s string) (time.Duration, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	m, err2 := strconv.Atoi(parts[1])
+	sec, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil || h < 0 || m < 0 || m > 59 || sec < 0 || sec >= 60 {
+		return 0, false
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second)), true
+}
+
+// parseEval reads an evaluation in pawns like "+0.35" or a mate distance like "#-3",
+// optionally followed by a search depth after a comma
+func parseEval(m *Move, s string) bool {
+	s, _, _ = strings.Cut(s, ",")
+	if rest, ok := strings.CutPrefix(s, "#"); ok {
+		mate, err := strconv.Atoi(rest)
+		if err != nil {
+			return false
+		}
+		m.Mate, m.Eval, m.HasEval = mate, 0, true
+		return true
+	}
+	eval, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return false
+	}
+	m.Eval, m.Mate, m.HasEval = eval, 0, true
+	return true
+}
+
+func joinComment(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + " " + b
+}
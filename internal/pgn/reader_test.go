@@ -0,0 +1,264 @@
+package pgn
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// readAll reads every game from the input, failing on any error.
+func readAll(t *testing.T, input string) []*Game {
+	t.Helper()
+	r := NewReader(strings.NewReader(input))
+	var games []*Game
+	for {
+		g, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return games
+		}
+		if err != nil {
+			t.Fatalf("Expected no error, got %v instead", err)
+		}
+		games = append(games, g)
+	}
+}
+
+// sans returns the SAN of each move of a line.
+func sans(moves []Move) []string {
+	s := make([]string, len(moves))
+	for i, m := range moves {
+		s[i] = m.SAN
+	}
+	return s
+}
+
+func TestReadGame(t *testing.T) {
+	input := `[Event "Casual game"]
+[Site "?"]
+[Result "1-0"]
+
+{Opening comment} 1. e4 {[%clk 0:05:00] [%eval 0.3]} e5 {[%clk 0:04:58.5]} 2. Nf3 Nc6
+3. Bb5!? $14 a6 (3... Nf6 4. O-O (4. d3) Nxe4 {[%eval #-12] Berlin}) 4. Ba4 1-0
+`
+	games := readAll(t, input)
+	if len(games) != 1 {
+		t.Fatalf("expected 1 game, got %d instead", len(games))
+	}
+	g := games[0]
+
+	wantTags := Tags{{"Event", "Casual game"}, {"Site", "?"}, {"Result", "1-0"}}
+	if !reflect.DeepEqual(g.Tags, wantTags) {
+		t.Errorf("expected tags %v, got %v instead", wantTags, g.Tags)
+	}
+	if g.Result != WhiteWins {
+		t.Errorf("expected result %s, got %s instead", WhiteWins, g.Result)
+	}
+	if g.Comment != "Opening comment" {
+		t.Errorf("expected game comment %q, got %q instead", "Opening comment", g.Comment)
+	}
+	if want := []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6", "Ba4"}; !reflect.DeepEqual(sans(g.Moves), want) {
+		t.Errorf("expected main line %v, got %v instead", want, sans(g.Moves))
+	}
+
+	e4, e5 := g.Moves[0], g.Moves[1]
+	if e4.Move.UCI() != "e2e4" {
+		t.Errorf("expected e2e4, got %s instead", e4.Move.UCI())
+	}
+	if !e4.HasClock || e4.Clock != 5*time.Minute || !e4.HasEval || e4.Eval != 0.3 || e4.Comment != "" {
+		t.Errorf("expected clock 5m and eval 0.3 without comment, got %+v instead", e4)
+	}
+	if !e5.HasClock || e5.Clock != 4*time.Minute+58500*time.Millisecond || e5.HasEval {
+		t.Errorf("expected clock 4m58.5s without eval, got %+v instead", e5)
+	}
+	if bb5 := g.Moves[4]; !reflect.DeepEqual(bb5.NAGs, []int{5, 14}) {
+		t.Errorf("expected NAGs [5 14], got %v instead", bb5.NAGs)
+	}
+
+	// 3... Nf6 replaces 3... a6, and 4. d3 replaces 4. O-O inside it
+	variations := g.Moves[5].Variations
+	if len(variations) != 1 {
+		t.Fatalf("expected 1 variation, got %d instead", len(variations))
+	}
+	if want := []string{"Nf6", "O-O", "Nxe4"}; !reflect.DeepEqual(sans(variations[0]), want) {
+		t.Errorf("expected variation %v, got %v instead", want, sans(variations[0]))
+	}
+	castle := variations[0][1]
+	if !castle.Move.Type.Has(board.Castle) {
+		t.Errorf("expected O-O to be a castling move, got %v", castle.Move.Type)
+	}
+	if len(castle.Variations) != 1 || !reflect.DeepEqual(sans(castle.Variations[0]), []string{"d3"}) {
+		t.Errorf("expected nested variation [d3], got %v instead", castle.Variations)
+	}
+	if nxe4 := variations[0][2]; nxe4.Mate != -12 || !nxe4.HasEval || nxe4.Comment != "Berlin" {
+		t.Errorf("expected mate in -12 with comment Berlin, got %+v instead", nxe4)
+	}
+}
+
+func TestReadGames(t *testing.T) {
+	input := `[Event "First"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+
+[Event "Second"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+[Result "1/2-1/2"]
+
+1. e4 Kd7 1/2-1/2
+
+[Event "No result marker"]
+[Result "1-0"]
+
+1. d4
+
+[Event "Empty"]
+
+*
+`
+	games := readAll(t, input)
+	if len(games) != 4 {
+		t.Fatalf("expected 4 games, got %d instead", len(games))
+	}
+	wantResults := []string{BlackWins, Draw, WhiteWins, Unfinished}
+	for i, g := range games {
+		if g.Result != wantResults[i] {
+			t.Errorf("game %d: expected result %s, got %s instead", i+1, wantResults[i], g.Result)
+		}
+	}
+	if fen := games[1].Start.ToFEN(); fen != "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1" {
+		t.Errorf("expected start position from the FEN tag, got %s instead", fen)
+	}
+	if len(games[2].Moves) != 1 || len(games[3].Moves) != 0 {
+		t.Errorf("expected 1 and 0 moves, got %d and %d instead", len(games[2].Moves), len(games[3].Moves))
+	}
+}
+
+func TestReadCommentsOutsideGames(t *testing.T) {
+	// A byte order mark and a header comment before the first tags, a trailing comment after the last game
+	input := "\uFEFF{Header}\n[Event \"First\"]\n\n1. e4 *\n\n{Trailer}\n"
+	games := readAll(t, input)
+	if len(games) != 1 {
+		t.Fatalf("expected 1 game, got %d instead", len(games))
+	}
+	g := games[0]
+	if event, _ := g.Tag("Event"); event != "First" || len(g.Moves) != 1 {
+		t.Errorf("expected First with 1 move, got %s with %d moves instead", event, len(g.Moves))
+	}
+	if g.Comment != "Header" {
+		t.Errorf("expected game comment %q, got %q instead", "Header", g.Comment)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		line, col int
+	}{
+		{"illegal move", "[Event \"?\"]\n\n1. e4 e5 2. Ke3 *\n", 3, 13},
+		{"malformed tag", "[Event ?]\n\n1. e4 *\n", 1, 8},
+		{"invalid FEN tag", "[FEN \"8/8 w - -\"]\n\n*\n", 1, 1},
+		{"unterminated variation", "1. e4 (1. d4 d5\n", 2, 1},
+		{"unbalanced parenthesis", "1. e4 ) e5 *\n", 1, 7},
+		{"variation before any move", "( 1. d4 ) 1. e4 *\n", 1, 1},
+		{"result inside a variation", "1. e4 (1. d4 1-0) *\n", 1, 14},
+		{"unknown annotation", "1. e4!!! *\n", 1, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tt.input)).Read()
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a *SyntaxError, got %v instead", err)
+			}
+			if syntaxErr.Line != tt.line || syntaxErr.Column != tt.col {
+				t.Errorf("expected error at %d:%d, got %d:%d instead (%v)", tt.line, tt.col, syntaxErr.Line, syntaxErr.Column, err)
+			}
+		})
+	}
+}
+
+func TestReadRecovers(t *testing.T) {
+	tests := []struct {
+		name   string
+		broken string
+	}{
+		{"illegal move", "[Event \"Broken\"]\n\n1. e4 e5 2. Qxf7 {illegal (} *\n\n"},
+		{"tag inside a variation", "[Event \"Broken\"]\n\n1. e4 (1. d4\n"},
+		{"malformed tag", "[Event \"Broken\"]\n[Site x]\n[Date \"2020.01.01\"]\n\n1. e4 e5 *\n\n"},
+		{"malformed tag without result", "[Event \"Broken\"]\n[Site x]\n[Date \"2020.01.01\"]\n\n1. e4 e5\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.broken + "[Event \"Fine\"]\n\n1. d4 d5 *\n\n[Event \"Last\"]\n\n1. c4 *\n"
+			r := NewReader(strings.NewReader(input))
+			if _, err := r.Read(); err == nil {
+				t.Fatalf("expected error for the broken game, got nil instead")
+			}
+			for _, want := range []struct {
+				event string
+				moves int
+			}{{"Fine", 2}, {"Last", 1}} {
+				g, err := r.Read()
+				if err != nil {
+					t.Fatalf("Expected no error, got %v instead", err)
+				}
+				if event, _ := g.Tag("Event"); event != want.event || len(g.Moves) != want.moves {
+					t.Errorf("expected %s with %d moves, got %s with %d moves instead", want.event, want.moves, event, len(g.Moves))
+				}
+			}
+			if _, err := r.Read(); !errors.Is(err, io.EOF) {
+				t.Errorf("expected io.EOF, got %v instead", err)
+			}
+		})
+	}
+}
+
+// repeatReader produces the same game over and over, never holding more than one copy in memory.
+type repeatReader struct {
+	game  string
+	count int
+	pos   int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	if r.count == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.game[r.pos:])
+	r.pos += n
+	if r.pos == len(r.game) {
+		r.pos = 0
+		r.count--
+	}
+	return n, nil
+}
+
+func TestReadStream(t *testing.T) {
+	const count = 2000
+	game := "[Event \"Rated\"]\n[Result \"1-0\"]\n\n1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6 4. Qxf7# 1-0\n\n"
+	r := NewReader(&repeatReader{game: game, count: count})
+	read := 0
+	for {
+		g, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("game %d: Expected no error, got %v instead", read+1, err)
+		}
+		if len(g.Moves) != 7 {
+			t.Fatalf("game %d: expected 7 moves, got %d instead", read+1, len(g.Moves))
+		}
+		read++
+	}
+	if read != count {
+		t.Errorf("expected %d games, got %d instead", count, read)
+	}
+}
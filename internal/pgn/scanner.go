@@ -0,0 +1,209 @@
+package pgn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+)
+
+type tokenKind uint8
+
+const (
+	tokenEOF      tokenKind = iota
+	tokenSymbol             // moves, move numbers, results, tag names and suffix annotations
+	tokenString             // quoted tag value, without the quotes
+	tokenPeriod             // "." after move numbers
+	tokenLBracket           // "[" opening a tag pair
+	tokenRBracket           // "]" closing a tag pair
+	tokenLParen             // "(" opening a variation
+	tokenRParen             // ")" closing a variation
+	tokenComment            // brace or rest of line comment, without delimiters
+	tokenNAG                // numeric annotation glyph, "$" followed by digits
+)
+
+type token struct {
+	kind      tokenKind
+	text      string
+	line, col int // position of the first character of the token
+}
+
+// scanner splits PGN text into tokens, reading from the underlying reader as needed.
+type scanner struct {
+	r         *bufio.Reader
+	line, col int // position of the next rune
+	prevCol   int // column before the last rune read, to unread it
+	peeked    *token
+}
+
+func newScanner(r io.Reader) *scanner {
+	return &scanner{r: bufio.NewReader(r), line: 1, col: 1}
+}
+
+func (s *scanner) readRune() (rune, error) {
+	c, _, err := s.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if c == '\uFEFF' && s.line == 1 && s.col == 1 {
+		// Byte order mark some editors write at the start of the file
+		return s.readRune()
+	}
+	s.prevCol = s.col
+	if c == '\n' {
+		s.line++
+		s.col = 1
+	} else {
+		s.col++
+	}
+	return c, nil
+}
+
+func (s *scanner) unreadRune(c rune) {
+	s.r.UnreadRune()
+	if c == '\n' {
+		s.line--
+	}
+	s.col = s.prevCol
+}
+
+// unread pushes back a token, returned again by the next call to next
+func (s *scanner) unread(t token) {
+	s.peeked = &t
+}
+
+// next returns the next token, a tokenEOF token at the end of the input.
+func (s *scanner) next() (token, error) {
+	if s.peeked != nil {
+		t := *s.peeked
+		s.peeked = nil
+		return t, nil
+	}
+
+	for {
+		line, col := s.line, s.col
+		c, err := s.readRune()
+		if errors.Is(err, io.EOF) {
+			return token{kind: tokenEOF, line: line, col: col}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+		t := token{line: line, col: col}
+
+		switch {
+		case unicode.IsSpace(c):
+			continue
+		case c == '%' && col == 1:
+			// Escaped line, ignored entirely
+			if _, err := s.readUntil('\n'); err != nil && !errors.Is(err, io.EOF) {
+				return token{}, err
+			}
+			continue
+		case c == '.':
+			t.kind = tokenPeriod
+		case c == '[':
+			t.kind = tokenLBracket
+		case c == ']':
+			t.kind = tokenRBracket
+		case c == '(':
+			t.kind = tokenLParen
+		case c == ')':
+			t.kind = tokenRParen
+		case c == '{':
+			t.kind = tokenComment
+			t.text, err = s.readUntil('}')
+			if errors.Is(err, io.EOF) {
+				return token{}, syntaxError(t, "unterminated comment")
+			}
+		case c == ';':
+			t.kind = tokenComment
+			t.text, err = s.readUntil('\n')
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+		case c == '"':
+			t.kind = tokenString
+			t.text, err = s.readString()
+			if errors.Is(err, io.EOF) {
+				return token{}, syntaxError(t, "unterminated string")
+			}
+		case c == '$':
+			t.kind = tokenNAG
+			t.text, err = s.readWhile(unicode.IsDigit)
+			if err == nil && t.text == "" {
+				return token{}, syntaxError(t, "missing digits after $")
+			}
+		case isSymbolRune(c):
+			t.kind = tokenSymbol
+			var rest string
+			rest, err = s.readWhile(isSymbolRune)
+			t.text = string(c) + rest
+		default:
+			return token{}, syntaxError(t, "unexpected character %q", c)
+		}
+		if err != nil {
+			return token{}, err
+		}
+		return t, nil
+	}
+}
+
+func isSymbolRune(c rune) bool {
+	return c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("_+#=:-/*!?", c))
+}
+
+// readUntil reads up to the delimiter, returning the text without it
+func (s *scanner) readUntil(delim rune) (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := s.readRune()
+		if err != nil {
+			return sb.String(), err
+		}
+		if c == delim {
+			return sb.String(), nil
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readWhile reads the runes matching the predicate, leaving the first one that does not
+func (s *scanner) readWhile(match func(rune) bool) (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := s.readRune()
+		if errors.Is(err, io.EOF) {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if !match(c) {
+			s.unreadRune(c)
+			return sb.String(), nil
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readString reads a quoted string after its opening quote, resolving \" and \\ escapes
+func (s *scanner) readString() (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := s.readRune()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if c, err = s.readRune(); err != nil {
+				return "", err
+			}
+		}
+		sb.WriteRune(c)
+	}
+}
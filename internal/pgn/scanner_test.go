@@ -0,0 +1,83 @@
+package pgn
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	input := `[Event "A \"quoted\" \\ name"]
+% escaped line ignored
+1. e4 {a comment
+on two lines} e5!? $14 (1... c5) ; rest of line
+2.Nf3 1-0`
+	want := []token{
+		{kind: tokenLBracket, line: 1, col: 1},
+		{kind: tokenSymbol, text: "Event", line: 1, col: 2},
+		{kind: tokenString, text: `A "quoted" \ name`, line: 1, col: 8},
+		{kind: tokenRBracket, line: 1, col: 30},
+		{kind: tokenSymbol, text: "1", line: 3, col: 1},
+		{kind: tokenPeriod, line: 3, col: 2},
+		{kind: tokenSymbol, text: "e4", line: 3, col: 4},
+		{kind: tokenComment, text: "a comment\non two lines", line: 3, col: 7},
+		{kind: tokenSymbol, text: "e5!?", line: 4, col: 15},
+		{kind: tokenNAG, text: "14", line: 4, col: 20},
+		{kind: tokenLParen, line: 4, col: 24},
+		{kind: tokenSymbol, text: "1", line: 4, col: 25},
+		{kind: tokenPeriod, line: 4, col: 26},
+		{kind: tokenPeriod, line: 4, col: 27},
+		{kind: tokenPeriod, line: 4, col: 28},
+		{kind: tokenSymbol, text: "c5", line: 4, col: 30},
+		{kind: tokenRParen, line: 4, col: 32},
+		{kind: tokenComment, text: " rest of line", line: 4, col: 34},
+		{kind: tokenSymbol, text: "2", line: 5, col: 1},
+		{kind: tokenPeriod, line: 5, col: 2},
+		{kind: tokenSymbol, text: "Nf3", line: 5, col: 3},
+		{kind: tokenSymbol, text: "1-0", line: 5, col: 7},
+		{kind: tokenEOF, line: 5, col: 10},
+	}
+	s := newScanner(strings.NewReader(input))
+	for i, w := range want {
+		got, err := s.next()
+		if err != nil {
+			t.Fatalf("token %d: Expected no error, got %v instead", i, err)
+		}
+		if got != w {
+			t.Errorf("token %d: expected %+v, got %+v instead", i, w, got)
+		}
+	}
+}
+
+func TestScannerError(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		line, col int
+	}{
+		{"unterminated comment", "1. e4 {never closed", 1, 7},
+		{"unterminated string", "[Event \"Test]\n", 1, 8},
+		{"NAG without digits", "1. e4 $ e5", 1, 7},
+		{"unexpected character", "1. e4 @", 1, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScanner(strings.NewReader(tt.input))
+			var err error
+			for err == nil {
+				var tok token
+				tok, err = s.next()
+				if err == nil && tok.kind == tokenEOF {
+					t.Fatalf("expected error, reached the end of input instead")
+				}
+			}
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a *SyntaxError, got %v instead", err)
+			}
+			if syntaxErr.Line != tt.line || syntaxErr.Column != tt.col {
+				t.Errorf("expected error at %d:%d, got %d:%d instead", tt.line, tt.col, syntaxErr.Line, syntaxErr.Column)
+			}
+		})
+	}
+}
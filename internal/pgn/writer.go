@@ -0,0 +1,249 @@
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// maxLineLength is the longest movetext line written, in characters
+const maxLineLength = 80
+
+// sevenTagRoster lists the tags every exported game has, in their mandatory order
+var sevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// Writer writes games in PGN export format.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a Writer writing games to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes the game in export format followed by a blank line. The Seven Tag Roster comes first,
+// filled with "?" when missing, then SetUp and FEN when the game does not begin from the initial
+// position or is a Chess960 game, written from Start, and the other tags in alphabetical order.
+// Chess960 games get a Variant tag when they do not have one.
+// Moves are checked for legality and written in SAN regardless of the SAN stored with them.
+func (w *Writer) Write(g *Game) error {
+	var sb strings.Builder
+	for _, t := range exportTags(g) {
+		fmt.Fprintf(&sb, "[%s \"%s\"]\n", t.Name, escapeTag(t.Value))
+	}
+	sb.WriteByte('\n')
+
+	t := &movetext{sb: &sb}
+	if g.Comment != "" {
+		t.comment(g.Comment)
+	}
+	if err := t.line(g.StartPosition(), g.Moves); err != nil {
+		return err
+	}
+	t.token(gameResult(g))
+	sb.WriteString("\n\n")
+
+	_, err := io.WriteString(w.w, sb.String())
+	return err
+}
+
+// exportTags returns the tags of the game in export order
+func exportTags(g *Game) []Tag {
+	var initial board.Board
+	initial.SetInitialBoard()
+	start := g.StartPosition()
+	customStart := start.ToFEN() != initial.ToFEN() || start.Chess960
+
+	tags := make([]Tag, 0, len(g.Tags)+len(sevenTagRoster)+2)
+	for _, name := range sevenTagRoster {
+		value, ok := g.Tag(name)
+		switch {
+		case name == "Result":
+			value = gameResult(g)
+		case name == "Date" && !ok:
+			value = "????.??.??"
+		case !ok:
+			value = "?"
+		}
+		tags = append(tags, Tag{Name: name, Value: value})
+	}
+
+	var others []Tag
+	for _, t := range g.Tags {
+		if isRosterTag(t.Name) || t.Name == "SetUp" || t.Name == "FEN" {
+			continue
+		}
+		others = append(others, t)
+	}
+	if _, ok := g.Tag("Variant"); start.Chess960 && !ok {
+		others = append(others, Tag{Name: "Variant", Value: "Chess960"})
+	}
+	if customStart {
+		tags = append(tags, Tag{Name: "SetUp", Value: "1"}, Tag{Name: "FEN", Value: start.ToFEN()})
+	}
+	sort.SliceStable(others, func(i, j int) bool { return others[i].Name < others[j].Name })
+	return append(tags, others...)
+}
+
+func isRosterTag(name string) bool {
+	for _, n := range sevenTagRoster {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func gameResult(g *Game) string {
+	if g.Result == "" {
+		return Unfinished
+	}
+	return g.Result
+}
+
+func escapeTag(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// movetext lays out movetext tokens in lines of at most maxLineLength characters
+type movetext struct {
+	sb      *strings.Builder
+	lineLen int
+	glue    bool // the next token follows the previous one without a space, after "("
+}
+
+func (t *movetext) token(s string) {
+	switch {
+	case t.lineLen == 0:
+	case t.lineLen+1+len(s) > maxLineLength:
+		t.sb.WriteByte('\n')
+		t.lineLen = 0
+	case !t.glue:
+		t.sb.WriteByte(' ')
+		t.lineLen++
+	}
+	t.sb.WriteString(s)
+	t.lineLen += len(s)
+	t.glue = false
+}
+
+// move writes a move with its optional number, keeping both on the same line
+func (t *movetext) move(number, san string) {
+	if number == "" {
+		t.token(san)
+		return
+	}
+	if t.lineLen > 0 && t.lineLen+1+len(number)+1+len(san) > maxLineLength {
+		t.sb.WriteByte('\n')
+		t.lineLen = 0
+	}
+	t.token(number)
+	t.token(san)
+}
+
+// closeParen writes ")" right after the previous token, wrapping only if it does not fit
+func (t *movetext) closeParen() {
+	if t.lineLen+1 > maxLineLength {
+		t.sb.WriteByte('\n')
+		t.lineLen = 0
+	}
+	t.sb.WriteByte(')')
+	t.lineLen++
+}
+
+// comment writes a brace comment word by word, so long comments wrap like the moves
+func (t *movetext) comment(text string) {
+	words := strings.Fields(strings.ReplaceAll(text, "}", ""))
+	if len(words) == 0 {
+		t.token("{}")
+		return
+	}
+	words[0] = "{" + words[0]
+	words[len(words)-1] += "}"
+	for _, word := range words {
+		t.token(word)
+	}
+}
+
+// line writes the moves played from b, with their annotations and variations
+func (t *movetext) line(b board.Board, moves []Move) error {
+	needNumber := true // black moves get a number at the start of a line and after comments or variations
+	for _, m := range moves {
+		if m.Before != "" {
+			t.comment(m.Before)
+			needNumber = true
+		}
+		mv, err := b.ParseUCIMove(m.Move.UCI())
+		if err != nil {
+			return fmt.Errorf("move %d: %v", b.FullMoveCount, err)
+		}
+		number := ""
+		if b.SideToMove == board.White {
+			number = strconv.Itoa(b.FullMoveCount) + "."
+		} else if needNumber {
+			number = strconv.Itoa(b.FullMoveCount) + "..."
+		}
+		needNumber = false
+
+		prev := b
+		t.move(number, b.MoveToSAN(mv))
+		if err := b.PlayMove(mv); err != nil {
+			return fmt.Errorf("move %d: %v", prev.FullMoveCount, err)
+		}
+		for _, nag := range m.NAGs {
+			t.token("$" + strconv.Itoa(nag))
+		}
+		if c := moveComment(m); c != "" {
+			t.comment(c)
+			needNumber = true
+		}
+		for _, v := range m.Variations {
+			t.token("(")
+			t.glue = true
+			if err := t.line(prev, v); err != nil {
+				return err
+			}
+			t.closeParen()
+			needNumber = true
+		}
+	}
+	return nil
+}
+
+// moveComment builds the comment after a move, with its clock and evaluation commands
+func moveComment(m Move) string {
+	var parts []string
+	if m.HasClock {
+		parts = append(parts, "[%clk "+formatClock(m.Clock)+"]")
+	}
+	if m.HasEval {
+		eval := strconv.FormatFloat(m.Eval, 'f', -1, 64)
+		if m.Mate != 0 {
+			eval = "#" + strconv.Itoa(m.Mate)
+		}
+		parts = append(parts, "[%eval "+eval+"]")
+	}
+	if m.Comment != "" {
+		parts = append(parts, m.Comment)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatClock writes a duration as h:mm:ss, with tenths of second when there are any
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	tenths := int64(d / (100 * time.Millisecond))
+	s := fmt.Sprintf("%d:%02d:%02d", tenths/36000, tenths/600%60, tenths/10%60)
+	if tenths%10 != 0 {
+		s += "." + strconv.FormatInt(tenths%10, 10)
+	}
+	return s
+}
@@ -0,0 +1,217 @@
+package pgn
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deadpyxel/cheesy/internal/board"
+)
+
+// writeGame returns the export format text of the game.
+func writeGame(t *testing.T, g *Game) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := NewWriter(&sb).Write(g); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	return sb.String()
+}
+
+// line builds moves from UCI strings played from b, without annotations.
+func line(t *testing.T, b board.Board, uci ...string) []Move {
+	t.Helper()
+	moves := make([]Move, len(uci))
+	for i, s := range uci {
+		m, err := b.ParseUCIMove(s)
+		if err != nil {
+			t.Fatalf("Expected no error parsing %s, got %v instead", s, err)
+		}
+		moves[i] = Move{Move: m}
+		if err := b.PlayMove(m); err != nil {
+			t.Fatalf("Expected no error playing %s, got %v instead", s, err)
+		}
+	}
+	return moves
+}
+
+func TestWrite(t *testing.T) {
+	g := &Game{
+		Tags:   []Tag{{"White", `Jane "JD" Doe`}, {"Annotator", "Engine"}, {"Event", "Club match"}, {"ECO", "C20"}},
+		Result: BlackWins,
+	}
+	g.Start.SetInitialBoard()
+	g.Moves = line(t, g.Start, "f2f3", "e7e5", "g2g4", "d8h4")
+	g.Moves[1].Clock, g.Moves[1].HasClock = 59*time.Second+500*time.Millisecond, true
+	g.Moves[2].NAGs = []int{4}
+	g.Moves[2].Comment = "Losing at once"
+	afterF3 := g.Start
+	if err := afterF3.PlayMove(g.Moves[0].Move); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	g.Moves[1].Variations = [][]Move{line(t, afterF3, "e7e6")}
+	g.Moves[1].Variations[0][0].Before = "Also good"
+
+	want := `[Event "Club match"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Jane \"JD\" Doe"]
+[Black "?"]
+[Result "0-1"]
+[Annotator "Engine"]
+[ECO "C20"]
+
+1. f3 e5 {[%clk 0:00:59.5]} ({Also good} 1... e6) 2. g4 $4 {Losing at once}
+2... Qh4# 0-1
+
+`
+	if got := writeGame(t, g); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestWriteSetUp(t *testing.T) {
+	start, err := board.ParseFEN("4k3/8/8/8/8/8/4P3/4K3 b - - 3 12")
+	if err != nil {
+		t.Fatalf("invalid test FEN: %v", err)
+	}
+	g := &Game{Start: *start, Result: Draw, Tags: []Tag{{"FEN", "stale"}}}
+	g.Moves = line(t, g.Start, "e8d7", "e2e4")
+
+	got := writeGame(t, g)
+	for _, want := range []string{"[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/4P3/4K3 b - - 3 12\"]\n", "\n12... Kd7 13. e4 1/2-1/2\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	// Games from the initial position have no SetUp or FEN tags
+	g = &Game{Tags: []Tag{{"SetUp", "1"}}}
+	g.Start.SetInitialBoard()
+	if got := writeGame(t, g); strings.Contains(got, "SetUp") || strings.Contains(got, "FEN") {
+		t.Errorf("expected no SetUp or FEN tags, got:\n%s", got)
+	}
+}
+
+func TestWriteZeroStart(t *testing.T) {
+	// A game built by hand without a start position begins from the initial one
+	var b board.Board
+	b.SetInitialBoard()
+	g := &Game{Tags: []Tag{{"Event", "By hand"}}, Moves: line(t, b, "e2e4", "e7e5"), Result: Unfinished}
+
+	got := writeGame(t, g)
+	if strings.Contains(got, "SetUp") || strings.Contains(got, "FEN") {
+		t.Errorf("expected no SetUp or FEN tags, got:\n%s", got)
+	}
+	if !strings.Contains(got, "\n1. e4 e5 *\n") {
+		t.Errorf("expected the moves from the initial position, got:\n%s", got)
+	}
+}
+
+func TestWriteChess960(t *testing.T) {
+	start, err := board.ParseFEN("4k3/8/8/8/8/8/8/R5KR w HA - 0 1")
+	if err != nil {
+		t.Fatalf("invalid test FEN: %v", err)
+	}
+	g := &Game{Start: *start, Result: Unfinished}
+	g.Moves = line(t, g.Start, "g1h1", "e8d8")
+
+	got := writeGame(t, g)
+	for _, want := range []string{"[FEN \"4k3/8/8/8/8/8/8/R5KR w KQ - 0 1\"]\n[Variant \"Chess960\"]\n", "\n1. O-O Kd8 *\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+	back := readAll(t, got)
+	if len(back) != 1 || !back[0].Start.Chess960 || !reflect.DeepEqual(sans(back[0].Moves), []string{"O-O", "Kd8"}) {
+		t.Fatalf("expected the written game to read back with the same Chess960 moves")
+	}
+	if m := back[0].Moves[0].Move; m != g.Moves[0].Move {
+		t.Errorf("expected castling move %v, got %v instead", g.Moves[0].Move, m)
+	}
+
+	// The classic setup played as Chess960 still needs the FEN and Variant tags to castle the Chess960 way
+	g = &Game{}
+	if err := g.Start.SetChess960Position(board.Chess960Standard); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+	got = writeGame(t, g)
+	if !strings.Contains(got, "[FEN \"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1\"]\n[Variant \"Chess960\"]\n") {
+		t.Errorf("expected FEN and Variant tags, got:\n%s", got)
+	}
+	if back := readAll(t, got); len(back) != 1 || !back[0].Start.Chess960 {
+		t.Errorf("expected the game to read back as Chess960")
+	}
+}
+
+func TestWriteWrapsLines(t *testing.T) {
+	input := `[Event "Long game"]
+
+1. e4 {A comment long enough to need several lines when written, since it goes past the limit} e5
+2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8
+10. d4 Nbd7 11. c4 c6 12. cxb5 axb5 13. Nc3 Bb7 14. Bg5 b4 15. Nb1 h6 16. Bh4 c5
+17. dxe5 Nxe4 18. Bxe7 Qxe7 19. exd6 Qf6 20. Nbd2 Nxd6 21. Nc4 Nxc4 22. Bxc4 Nb6 *
+`
+	games := readAll(t, input)
+	out := writeGame(t, games[0])
+	for i, l := range strings.Split(out, "\n") {
+		if len(l) > maxLineLength {
+			t.Errorf("line %d is %d characters long: %q", i+1, len(l), l)
+		}
+	}
+
+	// What was written reads back as the same game
+	back := readAll(t, out)
+	if len(back) != 1 || !reflect.DeepEqual(sans(back[0].Moves), sans(games[0].Moves)) {
+		t.Fatalf("expected the written game to read back with the same moves")
+	}
+	if back[0].Moves[0].Comment != games[0].Moves[0].Comment {
+		t.Errorf("expected comment %q, got %q instead", games[0].Moves[0].Comment, back[0].Moves[0].Comment)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	input := `[Event "Annotated"]
+[Site "?"]
+[Date "2024.01.31"]
+[Round "1"]
+[White "A"]
+[Black "B"]
+[Result "1-0"]
+
+{Start} 1. e4 $1 {[%clk 1:30:00] [%eval 0.25] Best by test} 1... c5 (1... e5
+2. Nf3 (2. f4 exf4) 2... Nc6) 2. Nf3 {[%eval #7]} 1-0
+
+`
+	games := readAll(t, input)
+	if got := writeGame(t, games[0]); got != input {
+		t.Errorf("expected:\n%s\ngot:\n%s", input, got)
+	}
+}
+
+func TestWriteIllegalMove(t *testing.T) {
+	g := &Game{Moves: []Move{{Move: board.Move{From: 12, To: 36}}}} // e2e5
+	g.Start.SetInitialBoard()
+	if err := NewWriter(&strings.Builder{}).Write(g); err == nil {
+		t.Errorf("expected error for illegal move, got nil instead")
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00:00"},
+		{5*time.Minute + 3*time.Second, "0:05:03"},
+		{2*time.Hour + 250*time.Millisecond, "2:00:00.2"},
+		{-time.Second, "0:00:00"},
+	}
+	for _, tt := range tests {
+		if got := formatClock(tt.d); got != tt.want {
+			t.Errorf("formatClock(%v) = %s; want %s", tt.d, got, tt.want)
+		}
+	}
+}